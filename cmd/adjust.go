@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newAdjustCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adjust <input> <output>",
+		Short: "调整亮度、对比度、饱和度、gamma 与色相",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			quality, _ := cmd.Flags().GetInt("quality")
+			opts := adjustOptionsFromFlags(cmd)
+			opts.Quality = quality
+			opts.Conflict = cfg.Base.Conflict
+			outPath, err := core.Adjust(args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	addAdjustFlags(cmd)
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
+
+func addAdjustFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("brightness", 1, "亮度倍数 (1 为不变)")
+	cmd.Flags().Float64("contrast", 1, "对比度倍数 (1 为不变)")
+	cmd.Flags().Float64("saturation", 1, "饱和度倍数 (0 为灰度)")
+	cmd.Flags().Float64("gamma", 1, "gamma 值 (大于 1 提亮暗部)")
+	cmd.Flags().Float64("hue", 0, "色相旋转角度")
+	cmd.Flags().Bool("auto-level", false, "自动色阶 (直方图拉伸)")
+	cmd.Flags().Bool("auto-white-balance", false, "自动白平衡 (灰度世界)")
+}
+
+func adjustOptionsFromFlags(cmd *cobra.Command) core.AdjustOptions {
+	brightness, _ := cmd.Flags().GetFloat64("brightness")
+	contrast, _ := cmd.Flags().GetFloat64("contrast")
+	saturation, _ := cmd.Flags().GetFloat64("saturation")
+	gamma, _ := cmd.Flags().GetFloat64("gamma")
+	hue, _ := cmd.Flags().GetFloat64("hue")
+	autoLevel, _ := cmd.Flags().GetBool("auto-level")
+	autoWhiteBalance, _ := cmd.Flags().GetBool("auto-white-balance")
+	return core.AdjustOptions{
+		Brightness:       brightness,
+		Contrast:         contrast,
+		Saturation:       saturation,
+		Gamma:            gamma,
+		Hue:              hue,
+		AutoLevel:        autoLevel,
+		AutoWhiteBalance: autoWhiteBalance,
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newAnimCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "anim",
+		Short: "动图工具",
+	}
+	cmd.AddCommand(
+		newAnimExtractCmd(),
+		newAnimAssembleCmd(),
+		newAnimSpeedCmd(),
+		newAnimOptimizeCmd(),
+	)
+	return cmd
+}
+
+func newAnimExtractCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "extract <input> <output-dir>",
+		Short: "导出动图帧为 PNG",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			outputs, err := core.ExtractFrames(args[0], args[1], core.ExtractFramesOptions{
+				Conflict: cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			if !quiet {
+				for _, outPath := range outputs {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "完成: %d 帧\n", len(outputs))
+			return nil
+		},
+	}
+}
+
+func newAnimAssembleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assemble <inputs...> -o <output>",
+		Short: "合成 GIF/WebP 动图",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			delay, _ := cmd.Flags().GetInt("delay")
+			loop, _ := cmd.Flags().GetInt("loop")
+			quality, _ := cmd.Flags().GetInt("quality")
+			inputs, err := collectInputs(args, cfg.Base.Recursive)
+			if err != nil {
+				return err
+			}
+			outPath, err := core.AssembleAnimation(inputs, output, core.AssembleOptions{
+				Format:   format,
+				Delay:    delay,
+				Loop:     loop,
+				Quality:  quality,
+				Conflict: cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().StringP("format", "f", "", "输出格式: gif|webp")
+	cmd.Flags().Int("delay", 100, "帧延迟(毫秒)")
+	cmd.Flags().Int("loop", 0, "播放次数 (0 为无限循环)")
+	cmd.Flags().IntP("quality", "q", 0, "WebP 质量 (1-100)")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func newAnimSpeedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "speed <input> <output>",
+		Short: "调整动图播放速度",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			factor, _ := cmd.Flags().GetFloat64("factor")
+			outPath, err := core.ChangeAnimationSpeed(args[0], args[1], core.SpeedOptions{
+				Factor:   factor,
+				Conflict: cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().Float64("factor", 1, "速度倍数 (2 为两倍速, 0.5 为半速)")
+	return cmd
+}
+
+func newAnimOptimizeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "optimize <input> <output>",
+		Short: "优化动图体积",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			colors, _ := cmd.Flags().GetInt("colors")
+			fuzz, _ := cmd.Flags().GetInt("fuzz")
+			outPath, err := core.OptimizeAnimation(args[0], args[1], core.OptimizeOptions{
+				Colors:   colors,
+				Fuzz:     fuzz,
+				Conflict: cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().Int("colors", 0, "GIF 调色板颜色数 (2-256, 0 为 256)")
+	cmd.Flags().Int("fuzz", 0, "判定重复帧的逐通道容差 (0-255)")
+	return cmd
+}
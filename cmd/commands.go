@@ -22,7 +22,19 @@ func init() {
 		newResizeCmd(),
 		newRotateCmd(),
 		newWatermarkCmd(),
+		newAdjustCmd(),
+		newFilterCmd(),
+		newPipelineCmd(),
+		newRedactCmd(),
+		newFrameCmd(),
+		newComposeCmd(),
 		newBatchCmd(),
+		newAnimCmd(),
+		newMergeCmd(),
+		newMontageCmd(),
+		newSpriteCmd(),
+		newTilesCmd(),
+		newIconsCmd(),
 		newRemoveWatermarkCmd(),
 		newRemoveBgCmd(),
 		newEnhanceCmd(),
@@ -61,14 +73,33 @@ func newConvertCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			pages, _ := cmd.Flags().GetString("pages")
+			dpi, _ := cmd.Flags().GetInt("dpi")
+			density, _ := cmd.Flags().GetInt("density")
+			width, _ := cmd.Flags().GetInt("width")
+			height, _ := cmd.Flags().GetInt("height")
 			cfg := CurrentConfig()
-			outPath, err := core.Convert(args[0], args[1], core.ConvertOptions{
+			opts := core.ConvertOptions{
+				Raw:       rawOptionsFromFlags(cmd),
 				Format:    format,
 				Quality:   quality,
 				Overwrite: overwrite,
 				Conflict:  cfg.Base.Conflict,
 				ICOSizes:  icoSizes,
-			})
+				Pages:     pages,
+				DPI:       dpi,
+				Density:   density,
+				Width:     width,
+				Height:    height,
+			}
+			if pages != "" {
+				outputs, err := core.ConvertPages(args[0], args[1], opts)
+				for _, outPath := range outputs {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+				return err
+			}
+			outPath, err := core.Convert(args[0], args[1], opts)
 			if err != nil {
 				return err
 			}
@@ -80,6 +111,12 @@ func newConvertCmd() *cobra.Command {
 	cmd.Flags().IntP("quality", "q", 85, "质量 (1-100)")
 	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
 	cmd.Flags().String("ico-sizes", "", "ICO 尺寸列表 (如 256,128,64)")
+	cmd.Flags().String("pages", "", "PDF/TIFF 页码 (如 1-5,8 或 all)，每页输出一张图像")
+	cmd.Flags().Int("dpi", 0, "PDF 渲染 DPI")
+	cmd.Flags().Int("density", 0, "SVG 渲染 DPI (默认 72)")
+	cmd.Flags().Int("width", 0, "SVG 渲染宽度")
+	cmd.Flags().Int("height", 0, "SVG 渲染高度")
+	addRawFlags(cmd)
 	return cmd
 }
 
@@ -107,6 +144,7 @@ func newCompressCmd() *cobra.Command {
 				Aggressive:     aggressive,
 				Conflict:       cfg.Base.Conflict,
 				DefaultQuality: cfg.Compress.DefaultQuality,
+				Raw:            rawOptionsFromFlags(cmd),
 			})
 			if err != nil {
 				return err
@@ -119,6 +157,7 @@ func newCompressCmd() *cobra.Command {
 	cmd.Flags().String("max-size", "", "最大文件大小")
 	cmd.Flags().StringP("output", "o", "", "输出路径")
 	cmd.Flags().Bool("aggressive", false, "激进压缩")
+	addRawFlags(cmd)
 	return cmd
 }
 
@@ -133,6 +172,7 @@ func newResizeCmd() *cobra.Command {
 			fit, _ := cmd.Flags().GetString("fit")
 			withoutEnlargement, _ := cmd.Flags().GetBool("without-enlargement")
 			keepRatio, _ := cmd.Flags().GetBool("keep-ratio")
+			quality, _ := cmd.Flags().GetInt("quality")
 			cfg := CurrentConfig()
 			outPath, err := core.Resize(args[0], args[1], core.ResizeOptions{
 				Width:              width,
@@ -140,7 +180,9 @@ func newResizeCmd() *cobra.Command {
 				Fit:                fit,
 				WithoutEnlargement: withoutEnlargement,
 				KeepRatio:          keepRatio,
+				Quality:            quality,
 				Conflict:           cfg.Base.Conflict,
+				Raw:                rawOptionsFromFlags(cmd),
 			})
 			if err != nil {
 				return err
@@ -154,6 +196,8 @@ func newResizeCmd() *cobra.Command {
 	cmd.Flags().StringP("fit", "f", "", "适应模式")
 	cmd.Flags().Bool("without-enlargement", true, "不放大")
 	cmd.Flags().Bool("keep-ratio", true, "保持比例")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	addRawFlags(cmd)
 	return cmd
 }
 
@@ -166,11 +210,13 @@ func newRotateCmd() *cobra.Command {
 			degrees, _ := cmd.Flags().GetInt("degrees")
 			flip, _ := cmd.Flags().GetBool("flip")
 			flop, _ := cmd.Flags().GetBool("flop")
+			quality, _ := cmd.Flags().GetInt("quality")
 			cfg := CurrentConfig()
 			outPath, err := core.Rotate(args[0], args[1], core.RotateOptions{
 				Degrees:  degrees,
 				Flip:     flip,
 				Flop:     flop,
+				Quality:  quality,
 				Conflict: cfg.Base.Conflict,
 			})
 			if err != nil {
@@ -183,18 +229,28 @@ func newRotateCmd() *cobra.Command {
 	cmd.Flags().IntP("degrees", "d", 0, "旋转角度")
 	cmd.Flags().Bool("flip", false, "水平翻转")
 	cmd.Flags().Bool("flop", false, "垂直翻转")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
 	return cmd
 }
 
 func newWatermarkCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "watermark <input> <logo> <output> | watermark <input> <output> --text \"...\"",
+		Use:   "watermark <input> <logo> <output> | watermark <input> <output> --text|--qr|--barcode \"...\" | watermark <input> <output> --invisible --payload ID",
 		Short: "添加水印",
 		Args: func(cmd *cobra.Command, args []string) error {
 			text, _ := cmd.Flags().GetString("text")
-			if text != "" {
+			qrPayload, _ := cmd.Flags().GetString("qr")
+			barcodeValue, _ := cmd.Flags().GetString("barcode")
+			invisible, _ := cmd.Flags().GetBool("invisible")
+			if invisible {
 				if len(args) != 2 {
-					return apperror.InvalidArgument("文本水印需要输入与输出参数", nil)
+					return apperror.InvalidArgument("隐形水印需要输入与输出参数", nil)
+				}
+				return nil
+			}
+			if text != "" || qrPayload != "" || barcodeValue != "" {
+				if len(args) != 2 {
+					return apperror.InvalidArgument("文本、二维码与条形码水印需要输入与输出参数", nil)
 				}
 				return nil
 			}
@@ -205,6 +261,26 @@ func newWatermarkCmd() *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := CurrentConfig()
+			invisible, _ := cmd.Flags().GetBool("invisible")
+			if invisible {
+				payload, _ := cmd.Flags().GetString("payload")
+				strength, _ := cmd.Flags().GetFloat64("strength")
+				quality, _ := cmd.Flags().GetInt("quality")
+				if quality == 0 {
+					quality = cfg.Compress.DefaultQuality
+				}
+				outPath, err := core.InvisibleWatermark(args[0], args[1], core.InvisibleWatermarkOptions{
+					Payload:  payload,
+					Strength: strength,
+					Quality:  quality,
+					Conflict: cfg.Base.Conflict,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				return nil
+			}
 			gravity, _ := cmd.Flags().GetString("gravity")
 			opacity, _ := cmd.Flags().GetFloat64("opacity")
 			scale, _ := cmd.Flags().GetFloat64("scale")
@@ -219,6 +295,13 @@ func newWatermarkCmd() *cobra.Command {
 			strokeWidth, _ := cmd.Flags().GetInt("stroke-width")
 			background, _ := cmd.Flags().GetString("background")
 			strokeMode, _ := cmd.Flags().GetString("stroke-mode")
+			qrPayload, _ := cmd.Flags().GetString("qr")
+			qrLevel, _ := cmd.Flags().GetString("qr-level")
+			barcodeValue, _ := cmd.Flags().GetString("barcode")
+			moduleSize, _ := cmd.Flags().GetInt("module-size")
+			quietZone, _ := cmd.Flags().GetInt("quiet-zone")
+			blend, _ := cmd.Flags().GetString("blend")
+			quality, _ := cmd.Flags().GetInt("quality")
 			if opacity <= 0 {
 				opacity = cfg.Watermark.DefaultOpacity
 			}
@@ -261,12 +344,15 @@ func newWatermarkCmd() *cobra.Command {
 			input := args[0]
 			var output string
 			logo := ""
-			if text != "" {
+			if text != "" || qrPayload != "" || barcodeValue != "" {
 				output = args[1]
 			} else {
 				logo = args[1]
 				output = args[2]
 			}
+			if err := expandWatermarkTemplates(input, 1, &text, &qrPayload, &barcodeValue); err != nil {
+				return err
+			}
 			outPath, err := core.Watermark(input, output, core.WatermarkOptions{
 				LogoPath:    logo,
 				Text:        text,
@@ -283,6 +369,13 @@ func newWatermarkCmd() *cobra.Command {
 				StrokeWidth: strokeWidth,
 				Background:  background,
 				StrokeMode:  strokeMode,
+				QR:          qrPayload,
+				QRLevel:     qrLevel,
+				Barcode:     barcodeValue,
+				ModuleSize:  moduleSize,
+				QuietZone:   quietZone,
+				Blend:       blend,
+				Quality:     quality,
 				Conflict:    cfg.Base.Conflict,
 			})
 			if err != nil {
@@ -297,7 +390,7 @@ func newWatermarkCmd() *cobra.Command {
 	cmd.Flags().Float64P("scale", "s", 0, "缩放比例")
 	cmd.Flags().Int("offset-x", 0, "水平偏移(px)")
 	cmd.Flags().Int("offset-y", 0, "垂直偏移(px)")
-	cmd.Flags().String("text", "", "文字水印")
+	cmd.Flags().String("text", "", "文字水印 (支持 {filename} 等模板变量)")
 	cmd.Flags().Int("font-size", 0, "文字水印字号(px)")
 	cmd.Flags().String("font", "", "文字水印字体")
 	cmd.Flags().String("font-file", "", "文字水印字体文件")
@@ -306,9 +399,41 @@ func newWatermarkCmd() *cobra.Command {
 	cmd.Flags().Int("stroke-width", 0, "文字水印描边宽度(px)")
 	cmd.Flags().String("background", "", "文字水印背景色")
 	cmd.Flags().String("stroke-mode", "", "描边模式: circle|8dir")
+	cmd.Flags().String("qr", "", "二维码水印内容")
+	cmd.Flags().String("qr-level", "M", "二维码纠错等级: L|M|Q|H")
+	cmd.Flags().String("barcode", "", "条形码水印内容 (Code128)")
+	cmd.Flags().Int("module-size", 0, "二维码/条形码模块尺寸(px, 0 为按 --scale 推算)")
+	cmd.Flags().Int("quiet-zone", 0, "二维码/条形码静区宽度(模块数, 0 为默认)")
+	cmd.Flags().String("blend", "", "混合模式: normal|multiply|screen|overlay|soft-light|difference")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	cmd.Flags().Bool("invisible", false, "嵌入隐形水印")
+	cmd.Flags().String("payload", "", "隐形水印内容 (最多 16 字节)")
+	cmd.Flags().Float64("strength", 1, "隐形水印强度倍数")
+	cmd.AddCommand(newWatermarkDetectCmd())
 	return cmd
 }
 
+func newWatermarkDetectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "detect <input>",
+		Short: "检测隐形水印",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := core.DetectWatermark(args[0])
+			if err != nil {
+				return err
+			}
+			if !result.Found {
+				fmt.Fprintf(cmd.OutOrStdout(), "未检测到隐形水印 (置信度: %.2f)\n", result.Confidence)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "内容: %s\n", result.Payload)
+			fmt.Fprintf(cmd.OutOrStdout(), "置信度: %.2f\n", result.Confidence)
+			return nil
+		},
+	}
+}
+
 func newBatchCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "batch <command> <pattern>",
@@ -333,7 +458,7 @@ func newBatchCmd() *cobra.Command {
 			success := 0
 			failed := 0
 			errOut := cmd.ErrOrStderr()
-			for _, input := range collected.Files {
+			for i, input := range collected.Files {
 				if verbose && !quiet {
 					fmt.Fprintf(cmd.OutOrStdout(), "处理: %s\n", input)
 				}
@@ -350,6 +475,7 @@ func newBatchCmd() *cobra.Command {
 						Quality:   quality,
 						Overwrite: false,
 						Conflict:  cfg.Base.Conflict,
+						Raw:       rawOptionsFromFlags(cmd),
 					})
 				case "compress":
 					quality, _ := cmd.Flags().GetInt("quality")
@@ -365,6 +491,7 @@ func newBatchCmd() *cobra.Command {
 						Aggressive:     aggressive,
 						Conflict:       cfg.Base.Conflict,
 						DefaultQuality: cfg.Compress.DefaultQuality,
+						Raw:            rawOptionsFromFlags(cmd),
 					})
 				case "resize":
 					width, _ := cmd.Flags().GetString("width")
@@ -372,28 +499,34 @@ func newBatchCmd() *cobra.Command {
 					fit, _ := cmd.Flags().GetString("fit")
 					withoutEnlargement, _ := cmd.Flags().GetBool("without-enlargement")
 					keepRatio, _ := cmd.Flags().GetBool("keep-ratio")
+					quality, _ := cmd.Flags().GetInt("quality")
 					_, err = core.Resize(input, outDir, core.ResizeOptions{
 						Width:              width,
 						Height:             height,
 						Fit:                fit,
 						WithoutEnlargement: withoutEnlargement,
 						KeepRatio:          keepRatio,
+						Quality:            quality,
 						Conflict:           cfg.Base.Conflict,
+						Raw:                rawOptionsFromFlags(cmd),
 					})
 				case "rotate":
 					degrees, _ := cmd.Flags().GetInt("degrees")
 					flip, _ := cmd.Flags().GetBool("flip")
 					flop, _ := cmd.Flags().GetBool("flop")
+					quality, _ := cmd.Flags().GetInt("quality")
 					_, err = core.Rotate(input, outDir, core.RotateOptions{
 						Degrees:  degrees,
 						Flip:     flip,
 						Flop:     flop,
+						Quality:  quality,
 						Conflict: cfg.Base.Conflict,
 					})
 				case "watermark":
 					gravity, _ := cmd.Flags().GetString("gravity")
 					opacity, _ := cmd.Flags().GetFloat64("opacity")
 					scale, _ := cmd.Flags().GetFloat64("scale")
+					quality, _ := cmd.Flags().GetInt("quality")
 					offsetX, _ := cmd.Flags().GetInt("offset-x")
 					offsetY, _ := cmd.Flags().GetInt("offset-y")
 					text, _ := cmd.Flags().GetString("text")
@@ -406,12 +539,23 @@ func newBatchCmd() *cobra.Command {
 					background, _ := cmd.Flags().GetString("background")
 					strokeMode, _ := cmd.Flags().GetString("stroke-mode")
 					logo, _ := cmd.Flags().GetString("logo")
-					if text == "" && logo == "" {
-						return apperror.InvalidArgument("批量水印需要 --logo 或 --text", nil)
+					qrPayload, _ := cmd.Flags().GetString("qr")
+					qrLevel, _ := cmd.Flags().GetString("qr-level")
+					barcodeValue, _ := cmd.Flags().GetString("barcode")
+					moduleSize, _ := cmd.Flags().GetInt("module-size")
+					quietZone, _ := cmd.Flags().GetInt("quiet-zone")
+					blend, _ := cmd.Flags().GetString("blend")
+					if text == "" && logo == "" && qrPayload == "" && barcodeValue == "" {
+						return apperror.InvalidArgument("批量水印需要 --logo、--text、--qr 或 --barcode", nil)
 					}
 					if text != "" && logo != "" {
 						return apperror.InvalidArgument("--logo 与 --text 不可同时使用", nil)
 					}
+					for _, value := range []string{text, qrPayload, barcodeValue} {
+						if err := core.ValidateTemplate(value); err != nil {
+							return err
+						}
+					}
 					if opacity <= 0 {
 						opacity = cfg.Watermark.DefaultOpacity
 					}
@@ -451,9 +595,14 @@ func newBatchCmd() *cobra.Command {
 					if strokeMode == "" {
 						strokeMode = cfg.Watermark.DefaultStrokeMode
 					}
+					wmText, wmQR, wmBarcode := text, qrPayload, barcodeValue
+					err = expandWatermarkTemplates(input, i+1, &wmText, &wmQR, &wmBarcode)
+					if err != nil {
+						break
+					}
 					_, err = core.Watermark(input, outDir, core.WatermarkOptions{
 						LogoPath:    logo,
-						Text:        text,
+						Text:        wmText,
 						Opacity:     opacity,
 						Scale:       scale,
 						Gravity:     gravity,
@@ -467,8 +616,33 @@ func newBatchCmd() *cobra.Command {
 						StrokeWidth: strokeWidth,
 						Background:  background,
 						StrokeMode:  strokeMode,
+						QR:          wmQR,
+						QRLevel:     qrLevel,
+						Barcode:     wmBarcode,
+						ModuleSize:  moduleSize,
+						QuietZone:   quietZone,
+						Blend:       blend,
+						Quality:     quality,
 						Conflict:    cfg.Base.Conflict,
 					})
+				case "adjust":
+					opts := adjustOptionsFromFlags(cmd)
+					opts.Quality, _ = cmd.Flags().GetInt("quality")
+					opts.Conflict = cfg.Base.Conflict
+					_, err = core.Adjust(input, outDir, opts)
+				case "filter":
+					opts := filterOptionsFromFlags(cmd)
+					opts.Quality, _ = cmd.Flags().GetInt("quality")
+					opts.Conflict = cfg.Base.Conflict
+					_, err = core.Filter(input, outDir, opts)
+				case "pipeline":
+					var opts core.PipelineOptions
+					opts, err = pipelineOptionsFromFlags(cmd)
+					if err != nil {
+						return err
+					}
+					opts.Conflict = cfg.Base.Conflict
+					_, err = core.Pipeline(input, outDir, opts)
 				default:
 					return apperror.InvalidArgument("不支持的批量命令", nil)
 				}
@@ -497,7 +671,7 @@ func newBatchCmd() *cobra.Command {
 	cmd.Flags().String("max-size", "", "最大文件大小")
 	cmd.Flags().Bool("aggressive", false, "激进压缩")
 	cmd.Flags().String("logo", "", "水印图像")
-	cmd.Flags().String("text", "", "文字水印")
+	cmd.Flags().String("text", "", "文字水印 (支持 {filename} 等模板变量)")
 	cmd.Flags().Int("font-size", 0, "文字水印字号(px)")
 	cmd.Flags().String("font", "", "文字水印字体")
 	cmd.Flags().String("font-file", "", "文字水印字体文件")
@@ -506,6 +680,12 @@ func newBatchCmd() *cobra.Command {
 	cmd.Flags().Int("stroke-width", 0, "文字水印描边宽度(px)")
 	cmd.Flags().String("background", "", "文字水印背景色")
 	cmd.Flags().String("stroke-mode", "", "描边模式: circle|8dir")
+	cmd.Flags().String("qr", "", "二维码水印内容")
+	cmd.Flags().String("qr-level", "M", "二维码纠错等级: L|M|Q|H")
+	cmd.Flags().String("barcode", "", "条形码水印内容 (Code128)")
+	cmd.Flags().Int("module-size", 0, "二维码/条形码模块尺寸(px, 0 为按 --scale 推算)")
+	cmd.Flags().Int("quiet-zone", 0, "二维码/条形码静区宽度(模块数, 0 为默认)")
+	cmd.Flags().String("blend", "", "混合模式: normal|multiply|screen|overlay|soft-light|difference")
 	cmd.Flags().String("width", "", "宽度")
 	cmd.Flags().String("height", "", "高度")
 	cmd.Flags().String("fit", "", "适应模式")
@@ -519,35 +699,44 @@ func newBatchCmd() *cobra.Command {
 	cmd.Flags().Float64P("scale", "s", 0, "缩放比例")
 	cmd.Flags().Int("offset-x", 0, "水平偏移(px)")
 	cmd.Flags().Int("offset-y", 0, "垂直偏移(px)")
+	addRawFlags(cmd)
+	addAdjustFlags(cmd)
+	addFilterFlags(cmd)
+	cmd.Flags().StringArray("step", nil, "pipeline 处理步骤 (可重复)")
 	return cmd
 }
 
-func newRemoveWatermarkCmd() *cobra.Command {
-	cmd := newNotImplementedCmd("remove-watermark <input>", "去除水印", true)
-	cmd.Flags().StringP("output", "o", "", "输出路径")
-	cmd.Flags().StringP("model", "m", "", "使用模型")
-	cmd.Flags().String("api-key", "", "API Key")
-	cmd.Flags().String("format", "", "输出格式")
-	return cmd
+func addRawFlags(cmd *cobra.Command) {
+	cmd.Flags().String("white-balance", "", "RAW 白平衡: camera|auto")
+	cmd.Flags().Float64("exposure", 0, "RAW 曝光补偿(EV)")
 }
 
-func newRemoveBgCmd() *cobra.Command {
-	cmd := newNotImplementedCmd("remove-bg <input>", "智能抠图", true)
-	cmd.Flags().StringP("output", "o", "", "输出路径")
-	cmd.Flags().StringP("model", "m", "", "使用模型")
-	cmd.Flags().Bool("matte", false, "保留边缘细节")
-	cmd.Flags().String("format", "", "输出格式")
-	return cmd
+func rawOptionsFromFlags(cmd *cobra.Command) core.RawOptions {
+	whiteBalance, _ := cmd.Flags().GetString("white-balance")
+	exposure, _ := cmd.Flags().GetFloat64("exposure")
+	return core.RawOptions{WhiteBalance: whiteBalance, Exposure: exposure}
 }
 
-func newEnhanceCmd() *cobra.Command {
-	cmd := newNotImplementedCmd("enhance <input>", "AI 图像增强", true)
-	cmd.Flags().IntP("scale", "s", 2, "放大倍数")
-	cmd.Flags().StringP("model", "m", "", "超分辨率模型")
-	cmd.Flags().Bool("denoise", false, "降噪")
-	cmd.Flags().Bool("sharpen", false, "锐化")
-	cmd.Flags().String("format", "", "输出格式")
-	return cmd
+func expandWatermarkTemplates(input string, index int, values ...*string) error {
+	var tmplCtx *core.TemplateContext
+	for _, value := range values {
+		if *value == "" || !core.HasTemplate(*value) {
+			continue
+		}
+		if tmplCtx == nil {
+			loaded, err := core.NewTemplateContext(input, index)
+			if err != nil {
+				return err
+			}
+			tmplCtx = &loaded
+		}
+		expanded, err := core.ExpandTemplate(*value, *tmplCtx)
+		if err != nil {
+			return err
+		}
+		*value = expanded
+	}
+	return nil
 }
 
 func newStyleTransferCmd() *cobra.Command {
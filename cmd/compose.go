@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newComposeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose <layout> <output>",
+		Short: "按 YAML/JSON 布局叠加图片、文字与矩形图层",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			data, _ := cmd.Flags().GetString("data")
+			quality, _ := cmd.Flags().GetInt("quality")
+			outputs, err := core.Compose(args[0], args[1], core.ComposeOptions{
+				DataPath: data,
+				Quality:  quality,
+				Conflict: cfg.Base.Conflict,
+			})
+			if !quiet {
+				for _, outPath := range outputs {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().String("data", "", "CSV 数据文件，每行生成一张 (表头为模板变量名)")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
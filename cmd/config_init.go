@@ -103,6 +103,11 @@ const defaultConfigYAML = "" +
 	"      api_key_env: GOOGLE_API_KEY\n" +
 	"      endpoint: https://generativelanguage.googleapis.com/v1\n" +
 	"\n" +
+	"    remove-bg:\n" +
+	"      provider: removebg\n" +
+	"      api_key_env: REMOVE_BG_API_KEY\n" +
+	"      endpoint: https://api.remove.bg/v1.0/removebg\n" +
+	"\n" +
 	"# OCR 文字识别配置\n" +
 	"ocr:\n" +
 	"  api_key: \"\"\n" +
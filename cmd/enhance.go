@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/internal/ai"
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"github.com/kiry163/image-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newEnhanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enhance <input>",
+		Short: "图像增强：放大、降噪与锐化，可调用超分辨率服务",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			scale, _ := cmd.Flags().GetInt("scale")
+			model, _ := cmd.Flags().GetString("model")
+			method, _ := cmd.Flags().GetString("method")
+			denoise, _ := cmd.Flags().GetBool("denoise")
+			denoiseMethod, _ := cmd.Flags().GetString("denoise-method")
+			sharpen, _ := cmd.Flags().GetBool("sharpen")
+			format, _ := cmd.Flags().GetString("format")
+			quality, _ := cmd.Flags().GetInt("quality")
+			if output == "" {
+				output = cfg.Base.OutputDir
+			}
+			opts := core.EnhanceOptions{
+				Scale:         scale,
+				Denoise:       denoise,
+				DenoiseMethod: denoiseMethod,
+				Sharpen:       sharpen,
+				Format:        format,
+				Quality:       quality,
+				Conflict:      cfg.Base.Conflict,
+			}
+			switch strings.ToLower(method) {
+			case "", "ai":
+				upscaler, err := enhanceUpscaler(cfg, model, method != "", ai.UpscaleOptions{
+					Scale:   scale,
+					Denoise: denoise,
+					Sharpen: sharpen,
+				})
+				if err != nil {
+					return err
+				}
+				opts.Upscaler = upscaler
+			case "local":
+				if model != "" {
+					return apperror.InvalidArgument("--method local 不可与 --model 同时使用", nil)
+				}
+			default:
+				return apperror.InvalidArgument("增强方式仅支持 local 或 ai", nil)
+			}
+			outPath, err := core.Enhance(args[0], output, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().IntP("scale", "s", 2, "放大倍数 (1-8)")
+	cmd.Flags().StringP("model", "m", "", "超分辨率模型 (ai.models 中的名称)")
+	cmd.Flags().String("method", "", "增强方式: local|ai (默认配置了超分辨率模型时为 ai)")
+	cmd.Flags().Bool("denoise", false, "降噪")
+	cmd.Flags().String("denoise-method", "bilateral", "本地降噪方式: bilateral|median")
+	cmd.Flags().Bool("sharpen", false, "锐化")
+	cmd.Flags().String("format", "", "输出格式")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
+
+// enhanceUpscaler 返回超分辨率服务调用；未指定模型且未配置可用模型时返回 nil，由本地处理。
+// required 为 true（显式 --method ai）时缺少模型视为错误。
+func enhanceUpscaler(cfg config.Config, modelName string, required bool, upscaleOpts ai.UpscaleOptions) (core.Upscaler, error) {
+	model, ok, err := selectTaskModel(cfg, modelName, "enhance", ai.IsUpscaleProvider, func(model config.AIModel) bool {
+		// http 为通用接口，只有显式标注 task: enhance 时才会被自动选中。
+		return strings.EqualFold(model.Task, "enhance") || model.Task == "" && strings.EqualFold(model.Provider, ai.UpscaleProviderStability)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if required {
+			return nil, apperror.ConfigError("未配置超分辨率模型，请在 ai.models 中添加 provider 为 stability 或 http（task: enhance）的模型", nil)
+		}
+		return nil, nil
+	}
+	apiKey := ""
+	if model.APIKeyEnv != "" {
+		apiKey = os.Getenv(model.APIKeyEnv)
+	}
+	client, err := ai.NewUpscaleClient(model.Provider, model.Endpoint, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return func(pngData []byte, scale int) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+		defer cancel()
+		upscaleOpts.Scale = scale
+		return client.Upscale(ctx, pngData, upscaleOpts)
+	}, nil
+}
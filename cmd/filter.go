@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newFilterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "filter <input> <output>",
+		Short: "滤镜：模糊、锐化、灰度、怀旧、反色、阈值与像素化",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			quality, _ := cmd.Flags().GetInt("quality")
+			opts := filterOptionsFromFlags(cmd)
+			opts.Quality = quality
+			opts.Conflict = cfg.Base.Conflict
+			outPath, err := core.Filter(args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	addFilterFlags(cmd)
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("blur", 0, "高斯模糊 sigma")
+	cmd.Flags().Float64("sharpen", 0, "USM 锐化 sigma")
+	cmd.Flags().Float64("sharpen-flat", 1, "平坦区域锐化强度")
+	cmd.Flags().Float64("sharpen-jagged", 2, "边缘区域锐化强度")
+	cmd.Flags().Bool("grayscale", false, "灰度")
+	cmd.Flags().Bool("sepia", false, "怀旧 (棕褐色)")
+	cmd.Flags().Bool("negate", false, "反色")
+	cmd.Flags().Int("threshold", 0, "黑白阈值 (1-255)")
+	cmd.Flags().Int("pixelate", 0, "像素化方块尺寸(px)")
+}
+
+func filterOptionsFromFlags(cmd *cobra.Command) core.FilterOptions {
+	blur, _ := cmd.Flags().GetFloat64("blur")
+	sharpen, _ := cmd.Flags().GetFloat64("sharpen")
+	sharpenFlat, _ := cmd.Flags().GetFloat64("sharpen-flat")
+	sharpenJagged, _ := cmd.Flags().GetFloat64("sharpen-jagged")
+	grayscale, _ := cmd.Flags().GetBool("grayscale")
+	sepia, _ := cmd.Flags().GetBool("sepia")
+	negate, _ := cmd.Flags().GetBool("negate")
+	threshold, _ := cmd.Flags().GetInt("threshold")
+	pixelate, _ := cmd.Flags().GetInt("pixelate")
+	return core.FilterOptions{
+		Blur:          blur,
+		Sharpen:       sharpen,
+		SharpenFlat:   sharpenFlat,
+		SharpenJagged: sharpenJagged,
+		Grayscale:     grayscale,
+		Sepia:         sepia,
+		Negate:        negate,
+		Threshold:     threshold,
+		Pixelate:      pixelate,
+	}
+}
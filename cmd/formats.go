@@ -34,6 +34,9 @@ func newFormatsCmd() *cobra.Command {
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "输入格式: %s\n", strings.Join(inputFormats, ", "))
 			fmt.Fprintf(cmd.OutOrStdout(), "输出格式: %s\n", strings.Join(outputFormats, ", "))
+			if len(filterFormats(inputFormats, "raw")) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "RAW 解码: %s\n", core.RAWDecoder())
+			}
 			fmt.Fprintln(cmd.OutOrStdout(), "转换支持:")
 			pairs := buildPairs(inputFormats, outputFormats)
 			for _, pair := range pairs {
@@ -58,23 +61,30 @@ func supportedFormats(input bool) []string {
 		{Name: "heif", Type: bimg.HEIF},
 		{Name: "avif", Type: bimg.AVIF},
 		{Name: "svg", Type: bimg.SVG},
+		{Name: "bmp", Type: core.BMP},
+		{Name: "qoi", Type: core.QOI},
+		{Name: "jxl", Type: core.JXL},
+		{Name: "raw", Type: core.RAW},
 	}
 	result := make([]string, 0, len(formats))
 	for _, item := range formats {
 		name := core.NormalizeFormat(item.Name)
 		if input {
-			if bimg.IsTypeSupported(item.Type) {
+			if core.IsTypeSupported(item.Type) {
 				result = append(result, name)
 			}
 			continue
 		}
-		if bimg.IsTypeSupportedSave(item.Type) {
+		if core.IsTypeSupportedSave(item.Type) {
 			result = append(result, name)
 		}
 	}
 	if !input && core.HasImageMagick() && bimg.IsTypeSupportedSave(bimg.PNG) {
 		result = append(result, "ico")
 	}
+	if !input {
+		result = append(result, "icns")
+	}
 	sort.Strings(result)
 	return result
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newFrameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "frame <input> <output>",
+		Short: "添加内边距、边框、圆角与投影",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			pad, _ := cmd.Flags().GetInt("pad")
+			padColor, _ := cmd.Flags().GetString("pad-color")
+			radius, _ := cmd.Flags().GetInt("radius")
+			shadow, _ := cmd.Flags().GetString("shadow")
+			border, _ := cmd.Flags().GetString("border")
+			flatten, _ := cmd.Flags().GetString("flatten")
+			quality, _ := cmd.Flags().GetInt("quality")
+			outPath, err := core.Frame(args[0], args[1], core.FrameOptions{
+				Pad:      pad,
+				PadColor: padColor,
+				Radius:   radius,
+				Shadow:   shadow,
+				Border:   border,
+				Flatten:  flatten,
+				Quality:  quality,
+				Conflict: cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().Int("pad", 0, "内边距(px)")
+	cmd.Flags().String("pad-color", "white", "内边距颜色 (none 为透明)")
+	cmd.Flags().Int("radius", 0, "圆角半径(px)")
+	cmd.Flags().String("shadow", "", "投影 x,y,blur,color，如 0,8,24,#0004")
+	cmd.Flags().String("border", "", "边框 width,color，如 2,#ddd")
+	cmd.Flags().String("flatten", "", "拍平到指定背景色（输出 JPEG 时需要）")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newIconsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "icons <logo> -o <output-dir>",
+		Short: "生成 iOS/Android/PWA/favicon/macOS 应用图标",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			targetsRaw, _ := cmd.Flags().GetString("targets")
+			background, _ := cmd.Flags().GetString("background")
+			padding, _ := cmd.Flags().GetFloat64("padding")
+			name, _ := cmd.Flags().GetString("name")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			if output == "" {
+				output = cfg.Base.OutputDir
+			}
+			targets, err := core.ParseIconTargets(targetsRaw)
+			if err != nil {
+				return err
+			}
+			outputs, err := core.Icons(args[0], output, core.IconsOptions{
+				Targets:    targets,
+				Background: background,
+				Padding:    padding,
+				Name:       name,
+				Overwrite:  overwrite,
+				Conflict:   cfg.Base.Conflict,
+			})
+			if !quiet {
+				for _, outPath := range outputs {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "完成: %d 个文件\n", len(outputs))
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出目录")
+	cmd.Flags().String("targets", "ios,android,pwa,favicon,macos", "目标平台 (逗号分隔)")
+	cmd.Flags().String("background", "", "不透明图标的背景色 (默认 white)")
+	cmd.Flags().Float64("padding", 0, "Logo 四周留白比例 (0-0.5)")
+	cmd.Flags().String("name", "", "PWA 应用名称 (默认取文件名)")
+	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
+	return cmd
+}
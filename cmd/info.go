@@ -40,11 +40,25 @@ func newInfoCmd() *cobra.Command {
 			if err != nil {
 				return apperror.InvalidInput("无法读取文件", err)
 			}
-			imageType := bimg.DetermineImageType(buf)
-			if imageType == bimg.UNKNOWN {
-				return apperror.UnsupportedFormat("无法识别输入格式", nil)
+			if entries, err := core.ReadICNS(buf); err == nil {
+				largest := entries[0]
+				for _, entry := range entries {
+					if entry.Width*entry.Height > largest.Width*largest.Height {
+						largest = entry
+					}
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "文件名: %s\n", filepath.Base(input))
+				fmt.Fprintf(cmd.OutOrStdout(), "格式: icns\n")
+				fmt.Fprintf(cmd.OutOrStdout(), "尺寸: %dx%d\n", largest.Width, largest.Height)
+				fmt.Fprintf(cmd.OutOrStdout(), "大小: %d bytes\n", fileInfo.Size())
+				fmt.Fprintf(cmd.OutOrStdout(), "图标数: %d\n", len(entries))
+				return nil
 			}
-			meta, err := bimg.Metadata(buf)
+			normalized, imageType, err := core.NormalizeImage(buf)
+			if err != nil {
+				return err
+			}
+			meta, err := bimg.Metadata(normalized)
 			if err != nil {
 				return apperror.InvalidInput("无法解析图像", err)
 			}
@@ -53,6 +67,18 @@ func newInfoCmd() *cobra.Command {
 			fmt.Fprintf(cmd.OutOrStdout(), "格式: %s\n", format)
 			fmt.Fprintf(cmd.OutOrStdout(), "尺寸: %dx%d\n", meta.Size.Width, meta.Size.Height)
 			fmt.Fprintf(cmd.OutOrStdout(), "大小: %d bytes\n", fileInfo.Size())
+			if pages, ok := core.PageCount(input, buf); ok {
+				fmt.Fprintf(cmd.OutOrStdout(), "页数: %d\n", pages)
+			}
+			if anim, ok := core.AnimationMetadata(buf); ok {
+				fmt.Fprintf(cmd.OutOrStdout(), "帧数: %d\n", anim.Frames)
+				fmt.Fprintf(cmd.OutOrStdout(), "时长: %.2fs\n", anim.Duration.Seconds())
+				if anim.LoopCount == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "循环: 无限\n")
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "循环: %d 次\n", anim.LoopCount)
+				}
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/batch"
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <inputs...> -o <output>",
+		Short: "合并图像为多页 PDF/TIFF",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			pageSize, _ := cmd.Flags().GetString("page-size")
+			margin, _ := cmd.Flags().GetFloat64("margin")
+			dpi, _ := cmd.Flags().GetInt("dpi")
+			quality, _ := cmd.Flags().GetInt("quality")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			inputs, err := collectInputs(args, cfg.Base.Recursive)
+			if err != nil {
+				return err
+			}
+			outPath, err := core.Merge(inputs, output, core.MergeOptions{
+				Format:    format,
+				PageSize:  pageSize,
+				Margin:    margin,
+				DPI:       dpi,
+				Quality:   quality,
+				Overwrite: overwrite,
+				Conflict:  cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			fmt.Fprintf(cmd.OutOrStdout(), "完成: %d 页\n", len(inputs))
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().StringP("format", "f", "", "输出格式: pdf|tiff")
+	cmd.Flags().String("page-size", "fit", "页面尺寸: A4|letter|fit")
+	cmd.Flags().Float64("margin", 0, "页边距 (毫米)")
+	cmd.Flags().Int("dpi", 300, "图像分辨率 (DPI)")
+	cmd.Flags().IntP("quality", "q", 0, "JPEG 重新压缩质量 (1-100，0 为保留原始 JPEG)")
+	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+// collectInputs 逐个展开参数中的目录与通配符，每组结果按自然顺序排序，参数间保持原有顺序。
+func collectInputs(args []string, recursive bool) ([]string, error) {
+	inputs := make([]string, 0, len(args))
+	for _, arg := range args {
+		collected, err := batch.Collect(arg, recursive)
+		if err != nil {
+			return nil, err
+		}
+		batch.NaturalSort(collected.Files)
+		inputs = append(inputs, collected.Files...)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newMontageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "montage <inputs...> -o <output>",
+		Short: "生成缩略图拼图 (contact sheet)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			columns, _ := cmd.Flags().GetInt("columns")
+			tile, _ := cmd.Flags().GetString("tile")
+			fit, _ := cmd.Flags().GetString("fit")
+			gap, _ := cmd.Flags().GetInt("gap")
+			label, _ := cmd.Flags().GetString("label")
+			fontSize, _ := cmd.Flags().GetInt("font-size")
+			fontFile, _ := cmd.Flags().GetString("font-file")
+			labelColor, _ := cmd.Flags().GetString("label-color")
+			background, _ := cmd.Flags().GetString("background")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+			quality, _ := cmd.Flags().GetInt("quality")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			inputs, err := collectInputs(args, cfg.Base.Recursive)
+			if err != nil {
+				return err
+			}
+			outputs, err := core.Montage(inputs, output, core.MontageOptions{
+				Format:     format,
+				Columns:    columns,
+				Tile:       tile,
+				Fit:        fit,
+				Gap:        gap,
+				Label:      label,
+				FontSize:   fontSize,
+				FontFile:   fontFile,
+				LabelColor: labelColor,
+				Background: background,
+				PerPage:    perPage,
+				Quality:    quality,
+				Overwrite:  overwrite,
+				Conflict:   cfg.Base.Conflict,
+			})
+			for _, outPath := range outputs {
+				fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().StringP("format", "f", "", "输出格式")
+	cmd.Flags().Int("columns", 6, "每行列数")
+	cmd.Flags().String("tile", "300x300", "单元格尺寸 (WxH)")
+	cmd.Flags().String("fit", "contain", "缩放模式: contain|cover|fill|inside|outside")
+	cmd.Flags().Int("gap", 10, "间距(px)")
+	cmd.Flags().String("label", "", "标签模板 (如 {filename})")
+	cmd.Flags().Int("font-size", 14, "标签字号")
+	cmd.Flags().String("font-file", "", "标签字体文件路径")
+	cmd.Flags().String("label-color", "", "标签颜色 (默认 #333333)")
+	cmd.Flags().String("background", "", "背景颜色 (默认 white)")
+	cmd.Flags().Int("per-page", 0, "每张拼图最多图像数 (0 为不分页)")
+	cmd.Flags().IntP("quality", "q", 85, "质量 (1-100)")
+	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
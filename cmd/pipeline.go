@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"github.com/spf13/cobra"
+)
+
+func newPipelineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipeline <input> <output>",
+		Short: "按顺序执行多个调整与滤镜步骤",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			opts, err := pipelineOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			opts.Conflict = cfg.Base.Conflict
+			outPath, err := core.Pipeline(args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringArray("step", nil, "处理步骤，如 \"adjust --contrast 1.2\" 或 \"filter --sharpen 1.5\" (可重复，按顺序执行)")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
+
+func pipelineOptionsFromFlags(cmd *cobra.Command) (core.PipelineOptions, error) {
+	specs, _ := cmd.Flags().GetStringArray("step")
+	quality, _ := cmd.Flags().GetInt("quality")
+	opts := core.PipelineOptions{Quality: quality}
+	if len(specs) == 0 {
+		return opts, apperror.InvalidArgument("需要至少一个 --step", nil)
+	}
+	for _, spec := range specs {
+		step, err := parsePipelineStep(spec)
+		if err != nil {
+			return opts, err
+		}
+		opts.Steps = append(opts.Steps, step)
+	}
+	return opts, nil
+}
+
+// parsePipelineStep 解析 "<adjust|filter> [参数...]" 形式的步骤，参数与对应命令的 flag 相同。
+func parsePipelineStep(spec string) (core.PipelineStep, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return core.PipelineStep{}, apperror.InvalidArgument("流水线步骤不能为空", nil)
+	}
+	step := &cobra.Command{Use: fields[0]}
+	switch fields[0] {
+	case "adjust":
+		addAdjustFlags(step)
+	case "filter":
+		addFilterFlags(step)
+	default:
+		return core.PipelineStep{}, apperror.InvalidArgument("不支持的流水线步骤: "+fields[0], nil)
+	}
+	if err := step.Flags().Parse(fields[1:]); err != nil {
+		return core.PipelineStep{}, apperror.InvalidArgument("流水线步骤参数无效: "+spec, err)
+	}
+	if step.Flags().NArg() > 0 {
+		return core.PipelineStep{}, apperror.InvalidArgument("流水线步骤参数无效: "+spec, nil)
+	}
+	if fields[0] == "adjust" {
+		opts := adjustOptionsFromFlags(step)
+		return core.PipelineStep{Adjust: &opts}, nil
+	}
+	opts := filterOptionsFromFlags(step)
+	return core.PipelineStep{Filter: &opts}, nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"regexp"
+	"time"
+
+	"github.com/kiry163/image-cli/internal/ai"
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"github.com/spf13/cobra"
+)
+
+func newRedactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redact <input> <output>",
+		Short: "遮挡敏感区域（矩形或 OCR 匹配文本）",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			rectValues, _ := cmd.Flags().GetStringArray("rect")
+			method, _ := cmd.Flags().GetString("method")
+			color, _ := cmd.Flags().GetString("color")
+			strength, _ := cmd.Flags().GetInt("strength")
+			quality, _ := cmd.Flags().GetInt("quality")
+			textPattern, _ := cmd.Flags().GetString("text-pattern")
+			rects := make([]image.Rectangle, 0, len(rectValues))
+			for _, value := range rectValues {
+				rect, err := core.ParseRect(value)
+				if err != nil {
+					return err
+				}
+				rects = append(rects, rect)
+			}
+			var locator core.TextLocator
+			if textPattern != "" {
+				pattern, err := regexp.Compile(textPattern)
+				if err != nil {
+					return apperror.InvalidArgument("文本正则无效", err)
+				}
+				if cfg.OCR.APIKey == "" {
+					return apperror.New("E202", "OCR API Key 未配置", "请在配置文件中设置 ocr.api_key 或使用环境变量 OCR_API_KEY", nil)
+				}
+				client, err := ai.NewOCRClient(cfg.OCR.APIKey, cfg.OCR.BaseURL, cfg.OCR.Model)
+				if err != nil {
+					return err
+				}
+				locator = func(pngData []byte) ([]core.TextBox, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+					defer cancel()
+					located, err := client.Locate(ctx, pngData)
+					if err != nil {
+						return nil, err
+					}
+					boxes := make([]core.TextBox, 0, len(located))
+					matched := 0
+					for _, box := range located {
+						boxes = append(boxes, core.TextBox{Text: box.Text, X1: box.X1, Y1: box.Y1, X2: box.X2, Y2: box.Y2})
+						if pattern.MatchString(box.Text) {
+							matched++
+						}
+					}
+					if !quiet {
+						fmt.Fprintf(cmd.OutOrStdout(), "识别文本: %d 段, 匹配: %d 段\n", len(located), matched)
+					}
+					return boxes, nil
+				}
+			}
+			outPath, err := core.Redact(args[0], args[1], core.RedactOptions{
+				Rects:       rects,
+				TextPattern: textPattern,
+				Locator:     locator,
+				Method:      method,
+				Color:       color,
+				Strength:    strength,
+				Quality:     quality,
+				Conflict:    cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringArray("rect", nil, "遮挡区域 x,y,w,h (可重复)")
+	cmd.Flags().String("method", "pixelate", "遮挡方式: blur|pixelate|fill")
+	cmd.Flags().String("color", "black", "填充颜色 (仅 fill)")
+	cmd.Flags().Int("strength", 0, "模糊半径或像素块尺寸(px, 0 为按区域大小推算)")
+	cmd.Flags().String("text-pattern", "", "使用 OCR 定位并遮挡匹配该正则的文本")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// ocrStandIn 模拟 OpenAI 兼容的 chat completions 接口，返回固定的 grounding 结果并记录请求次数。
+type ocrStandIn struct {
+	content  string
+	requests int
+	images   []string
+}
+
+func (s *ocrStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/chat/completions" {
+		http.NotFound(w, r)
+		return
+	}
+	s.requests++
+	var req struct {
+		Messages []struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+		for _, message := range req.Messages {
+			// system 消息的 content 为字符串，带图片的 user 消息为内容片段数组。
+			var parts []struct {
+				ImageURL struct {
+					URL string `json:"url"`
+				} `json:"image_url"`
+			}
+			if json.Unmarshal(message.Content, &parts) != nil {
+				continue
+			}
+			for _, part := range parts {
+				if part.ImageURL.URL != "" {
+					s.images = append(s.images, part.ImageURL.URL)
+				}
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":      "chatcmpl-test",
+		"object":  "chat.completion",
+		"choices": []map[string]any{{"index": 0, "finish_reason": "stop", "message": map[string]any{"role": "assistant", "content": s.content}}},
+	})
+}
+
+func setupRedact(t *testing.T, content string) (*ocrStandIn, string, string) {
+	t.Helper()
+	standIn := &ocrStandIn{content: content}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	cfgText := "ocr:\n  api_key: test-key\n  base_url: " + server.URL + "/v1\n"
+	if err := os.WriteFile(cfgPath, []byte(cfgText), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(input, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return standIn, cfgPath, input
+}
+
+func runRedact(t *testing.T, args ...string) error {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(append([]string{"redact"}, args...))
+	t.Cleanup(func() { rootCmd.SetArgs(nil) })
+	return rootCmd.Execute()
+}
+
+func TestRedactTextPatternRedactsWholeBox(t *testing.T) {
+	standIn, cfgPath, input := setupRedact(t, "<|ref|>联系: alice@example.com<|/ref|><|det|>[[100, 200, 500, 400]]<|/det|>\n<|ref|>Hello<|/ref|><|det|>[[600, 600, 900, 800]]<|/det|>")
+	output := filepath.Join(filepath.Dir(input), "out.png")
+	err := runRedact(t, input, output, "--config", cfgPath, "--text-pattern", `[\w.+-]+@[\w-]+\.[\w.]+`, "--method", "fill", "--color", "#ff0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standIn.requests != 1 || len(standIn.images) != 1 || !strings.HasPrefix(standIn.images[0], "data:image/png;base64,") {
+		t.Fatalf("OCR requests = %d, images = %d, want one PNG data URL", standIn.requests, len(standIn.images))
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := color.NRGBA{R: 255, A: 255}
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	// 框的左端是 "联系:"，按字符比例截取时会被漏掉。
+	for _, p := range []image.Point{{22, 22}, {60, 30}, {98, 38}} {
+		if got := color.NRGBAModel.Convert(img.At(p.X, p.Y)); got != red {
+			t.Errorf("pixel %v = %v, want redacted", p, got)
+		}
+	}
+	for _, p := range []image.Point{{150, 70}, {5, 90}} {
+		if got := color.NRGBAModel.Convert(img.At(p.X, p.Y)); got != white {
+			t.Errorf("pixel %v = %v, want untouched", p, got)
+		}
+	}
+}
+
+func TestRedactFailsWithoutMatches(t *testing.T) {
+	_, cfgPath, input := setupRedact(t, "<|ref|>Hello<|/ref|><|det|>[[100, 200, 500, 400]]<|/det|>")
+	output := filepath.Join(filepath.Dir(input), "out.png")
+	err := runRedact(t, input, output, "--config", cfgPath, "--text-pattern", `\d{11}`, "--method", "pixelate")
+	if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != "E001" || apperror.ExitCode(err) == 0 {
+		t.Fatalf("err = %v, want E001 with non-zero exit", err)
+	}
+	if _, statErr := os.Stat(output); !os.IsNotExist(statErr) {
+		t.Fatalf("output was written despite no matches")
+	}
+}
+
+func TestRedactResolvesOutputBeforeOCR(t *testing.T) {
+	standIn, cfgPath, input := setupRedact(t, "<|ref|>alice@example.com<|/ref|><|det|>[[100, 200, 500, 400]]<|/det|>")
+	output := filepath.Join(filepath.Dir(input), "exists.png")
+	if err := os.WriteFile(output, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := runRedact(t, input, output, "--config", cfgPath, "--text-pattern", `@`, "--method", "pixelate")
+	if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.OutputExists("").Code {
+		t.Fatalf("err = %v, want output conflict error", err)
+	}
+	if standIn.requests != 0 {
+		t.Fatalf("OCR requests = %d, want none when the output already exists", standIn.requests)
+	}
+}
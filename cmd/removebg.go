@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/internal/ai"
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"github.com/kiry163/image-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveBgCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-bg <input>",
+		Short: "抠图：本地去除纯色背景或调用 AI 服务",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			method, _ := cmd.Flags().GetString("method")
+			tolerance, _ := cmd.Flags().GetFloat64("tolerance")
+			feather, _ := cmd.Flags().GetInt("feather")
+			keyColor, _ := cmd.Flags().GetString("color")
+			model, _ := cmd.Flags().GetString("model")
+			matte, _ := cmd.Flags().GetBool("matte")
+			maskOnly, _ := cmd.Flags().GetBool("mask-only")
+			format, _ := cmd.Flags().GetString("format")
+			if output == "" {
+				output = cfg.Base.OutputDir
+			}
+			if format == "" {
+				format = cfg.AI.Output.RemoveBGFormat
+			}
+			if method == "" && model != "" {
+				method = "ai"
+			}
+			var outPath string
+			var err error
+			if strings.EqualFold(method, "ai") {
+				outPath, err = removeBgWithAI(cfg, args[0], output, model, ai.SegmentOptions{
+					MaskOnly: maskOnly,
+					Matte:    matte,
+				}, core.CutoutOptions{
+					MaskOnly: maskOnly,
+					Format:   format,
+					Conflict: cfg.Base.Conflict,
+				})
+			} else {
+				outPath, err = core.RemoveBackground(args[0], output, core.RemoveBgOptions{
+					Method:    method,
+					Tolerance: tolerance,
+					Feather:   feather,
+					KeyColor:  keyColor,
+					MaskOnly:  maskOnly,
+					Format:    format,
+					Conflict:  cfg.Base.Conflict,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().String("method", "", "抠图方式: flood|chroma|ai (默认 flood，指定 --model 时为 ai)")
+	cmd.Flags().Float64("tolerance", 12, "背景颜色容差 (0-100)")
+	cmd.Flags().Int("feather", 2, "边缘羽化半径(px)")
+	cmd.Flags().String("color", "", "背景/键控颜色 (默认取图像边缘颜色)")
+	cmd.Flags().StringP("model", "m", "", "使用模型 (ai.models 中的名称)")
+	cmd.Flags().Bool("matte", false, "保留边缘细节 (半透明发丝等)")
+	cmd.Flags().Bool("mask-only", false, "仅输出灰度遮罩")
+	cmd.Flags().String("format", "", "输出格式 (默认取 ai.output.remove_bg_format)")
+	return cmd
+}
+
+func removeBgWithAI(cfg config.Config, input, output, modelName string, segmentOpts ai.SegmentOptions, cutoutOpts core.CutoutOptions) (string, error) {
+	model, err := segmentModel(cfg, modelName)
+	if err != nil {
+		return "", err
+	}
+	apiKey := ""
+	if model.APIKeyEnv != "" {
+		apiKey = os.Getenv(model.APIKeyEnv)
+	}
+	client, err := ai.NewSegmentClient(model.Provider, model.Endpoint, apiKey)
+	if err != nil {
+		return "", err
+	}
+	return core.ApplyCutout(input, output, func(pngData []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+		defer cancel()
+		return client.RemoveBackground(ctx, pngData, segmentOpts)
+	}, cutoutOpts)
+}
+
+// segmentModel 选择抠图模型：优先使用指定名称，其次 ai.default_model，最后按名称顺序取第一个支持抠图的模型。
+func segmentModel(cfg config.Config, name string) (config.AIModel, error) {
+	model, ok, err := selectTaskModel(cfg, name, "remove-bg", ai.IsSegmentProvider, func(model config.AIModel) bool {
+		return strings.EqualFold(model.Task, "remove-bg") || model.Task == ""
+	})
+	if err != nil {
+		return config.AIModel{}, err
+	}
+	if !ok {
+		return config.AIModel{}, apperror.ConfigError("未配置抠图模型，请在 ai.models 中添加 provider 为 removebg 或 http 的模型", nil)
+	}
+	return model, nil
+}
+
+// selectTaskModel 按名称或自动选择用于 task 的模型。指定名称时要求提供方支持且 task 不冲突；
+// 未指定时依次尝试 ai.default_model 与按名称排序后第一个满足 match 的模型。配置中的模型名称会被统一转为小写。
+func selectTaskModel(cfg config.Config, name, task string, capable func(provider string) bool, match func(config.AIModel) bool) (config.AIModel, bool, error) {
+	if name != "" {
+		model, ok := cfg.AI.Models[strings.ToLower(name)]
+		if !ok {
+			return config.AIModel{}, false, apperror.ConfigError("未找到模型配置: ai.models."+name, nil)
+		}
+		if !capable(model.Provider) || model.Task != "" && !strings.EqualFold(model.Task, task) {
+			return config.AIModel{}, false, apperror.ConfigError(fmt.Sprintf("模型 %s 不支持 %s", name, task), nil)
+		}
+		return model, true, nil
+	}
+	if model, ok := cfg.AI.Models[strings.ToLower(cfg.AI.DefaultModel)]; ok && capable(model.Provider) && match(model) {
+		return model, true, nil
+	}
+	names := make([]string, 0, len(cfg.AI.Models))
+	for candidate := range cfg.AI.Models {
+		names = append(names, candidate)
+	}
+	sort.Strings(names)
+	for _, candidate := range names {
+		if model := cfg.AI.Models[candidate]; capable(model.Provider) && match(model) {
+			return model, true, nil
+		}
+	}
+	return config.AIModel{}, false, nil
+}
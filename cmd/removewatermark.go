@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/internal/ai"
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"github.com/kiry163/image-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveWatermarkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-watermark <input>",
+		Short: "去除水印：本地修复小面积水印或调用 AI 修复服务",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			maskPath, _ := cmd.Flags().GetString("mask")
+			rectValues, _ := cmd.Flags().GetStringArray("rect")
+			dilate, _ := cmd.Flags().GetInt("dilate")
+			radius, _ := cmd.Flags().GetInt("radius")
+			method, _ := cmd.Flags().GetString("method")
+			model, _ := cmd.Flags().GetString("model")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			format, _ := cmd.Flags().GetString("format")
+			quality, _ := cmd.Flags().GetInt("quality")
+			if output == "" {
+				output = cfg.Base.OutputDir
+			}
+			rects := make([]image.Rectangle, 0, len(rectValues))
+			for _, value := range rectValues {
+				rect, err := core.ParseRect(value)
+				if err != nil {
+					return err
+				}
+				rects = append(rects, rect)
+			}
+			opts := core.RemoveWatermarkOptions{
+				MaskPath: maskPath,
+				Rects:    rects,
+				Dilate:   dilate,
+				Radius:   radius,
+				Format:   format,
+				Quality:  quality,
+				Conflict: cfg.Base.Conflict,
+			}
+			switch strings.ToLower(method) {
+			case "", "ai":
+				inpainter, err := watermarkInpainter(cfg, model, apiKey, method != "")
+				if err != nil {
+					return err
+				}
+				opts.Inpainter = inpainter
+			case "local":
+				if model != "" {
+					return apperror.InvalidArgument("--method local 不可与 --model 同时使用", nil)
+				}
+			default:
+				return apperror.InvalidArgument("去水印方式仅支持 local 或 ai", nil)
+			}
+			outPath, err := core.RemoveWatermark(args[0], output, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().String("mask", "", "水印遮罩图 (白色为水印区域)")
+	cmd.Flags().StringArray("rect", nil, "水印区域 x,y,w,h (可重复)")
+	cmd.Flags().Int("dilate", 2, "遮罩向外扩展(px)")
+	cmd.Flags().Int("radius", 5, "本地修复取样半径(px)")
+	cmd.Flags().String("method", "", "去水印方式: local|ai (默认配置了修复模型时为 ai)")
+	cmd.Flags().StringP("model", "m", "", "使用模型 (ai.models 中的名称)")
+	cmd.Flags().String("api-key", "", "API Key (默认读取模型 api_key_env 指定的环境变量)")
+	cmd.Flags().String("format", "", "输出格式")
+	cmd.Flags().IntP("quality", "q", 0, "质量 (1-100)")
+	return cmd
+}
+
+// watermarkInpainter 返回图像修复服务调用；未指定模型且未配置 task 为 remove-watermark 的模型时返回 nil，由本地修复。
+// required 为 true（显式 --method ai）时缺少模型视为错误。
+func watermarkInpainter(cfg config.Config, modelName, apiKey string, required bool) (core.Inpainter, error) {
+	model, ok, err := selectTaskModel(cfg, modelName, "remove-watermark", ai.IsInpaintProvider, func(model config.AIModel) bool {
+		// stability 与 http 均可用于其他用途，只有显式标注 task 时才会被自动选中。
+		return strings.EqualFold(model.Task, "remove-watermark")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if required {
+			return nil, apperror.ConfigError("未配置图像修复模型，请在 ai.models 中添加 provider 为 stability 或 http 且 task 为 remove-watermark 的模型", nil)
+		}
+		return nil, nil
+	}
+	if apiKey == "" && model.APIKeyEnv != "" {
+		apiKey = os.Getenv(model.APIKeyEnv)
+	}
+	client, err := ai.NewInpaintClient(model.Provider, model.Endpoint, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return func(pngData, mask []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+		defer cancel()
+		return client.Inpaint(ctx, pngData, mask)
+	}, nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newSpriteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sprite <inputs...> -o <output>",
+		Short: "生成 CSS 雪碧图",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			padding, _ := cmd.Flags().GetInt("padding")
+			retina, _ := cmd.Flags().GetBool("retina")
+			style, _ := cmd.Flags().GetString("style")
+			prefix, _ := cmd.Flags().GetString("prefix")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			inputs, err := collectInputs(args, cfg.Base.Recursive)
+			if err != nil {
+				return err
+			}
+			result, err := core.Sprite(inputs, output, core.SpriteOptions{
+				Format:    format,
+				Padding:   padding,
+				Retina:    retina,
+				Style:     style,
+				Prefix:    prefix,
+				Overwrite: overwrite,
+				Conflict:  cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			for _, outPath := range []string{result.Image, result.Retina, result.Stylesheet, result.Manifest} {
+				if outPath != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出路径")
+	cmd.Flags().StringP("format", "f", "", "输出格式: png|webp")
+	cmd.Flags().Int("padding", 2, "图标间距(px)")
+	cmd.Flags().Bool("retina", false, "输入视为 2x 素材，同时输出 1x 与 @2x 雪碧图")
+	cmd.Flags().String("style", "css", "样式格式: css|scss")
+	cmd.Flags().String("prefix", "icon", "CSS 类名前缀")
+	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kiry163/image-cli/internal/core"
+	"github.com/spf13/cobra"
+)
+
+func newTilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tiles <input> -o <output-dir>",
+		Short: "生成缩放金字塔瓦片或网格切图",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := CurrentConfig()
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			quality, _ := cmd.Flags().GetInt("quality")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+			grid, _ := cmd.Flags().GetString("grid")
+			if output == "" {
+				output = cfg.Base.OutputDir
+			}
+			if grid != "" {
+				outputs, err := core.SplitGrid(args[0], output, core.GridOptions{
+					Grid:      grid,
+					Format:    format,
+					Quality:   quality,
+					Overwrite: overwrite,
+					Conflict:  cfg.Base.Conflict,
+				})
+				for _, outPath := range outputs {
+					fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+				}
+				return err
+			}
+			tileSize, _ := cmd.Flags().GetInt("tile-size")
+			overlap, _ := cmd.Flags().GetInt("overlap")
+			layout, _ := cmd.Flags().GetString("layout")
+			outPath, err := core.Tiles(args[0], output, core.TilesOptions{
+				TileSize:  tileSize,
+				Overlap:   overlap,
+				Layout:    layout,
+				Format:    format,
+				Quality:   quality,
+				Overwrite: overwrite,
+				Conflict:  cfg.Base.Conflict,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "输出: %s\n", outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "输出目录")
+	cmd.Flags().Int("tile-size", 256, "瓦片尺寸(px)")
+	cmd.Flags().Int("overlap", 1, "瓦片重叠像素 (仅 dzi)")
+	cmd.Flags().String("layout", "dzi", "金字塔布局: dzi|zoomify|google")
+	cmd.Flags().StringP("format", "f", "", "瓦片格式: jpg|png|webp (网格模式默认与输入相同)")
+	cmd.Flags().IntP("quality", "q", 85, "质量 (1-100)")
+	cmd.Flags().String("grid", "", "按网格等分切图 (如 3x3)，忽略金字塔参数")
+	cmd.Flags().Bool("overwrite", false, "覆盖已存在文件")
+	return cmd
+}
@@ -3,11 +3,13 @@ module github.com/kiry163/image-cli
 go 1.23.12
 
 require (
+	github.com/boombuler/barcode v1.1.0
 	github.com/h2non/bimg v1.1.9
 	github.com/sashabaranov/go-openai v1.32.0
 	github.com/spf13/cobra v1.8.0
 	github.com/spf13/viper v1.18.2
 	golang.org/x/image v0.18.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -30,5 +32,4 @@ require (
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
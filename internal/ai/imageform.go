@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// formFile 为 multipart 请求中的一个文件字段。
+type formFile struct {
+	field    string
+	filename string
+	data     []byte
+}
+
+// imageRequest 描述一次上传图像并返回图像的服务调用，各客户端只需提供文件、表单字段与请求头。
+// jsonKeys 为 JSON 响应中依次尝试的 base64 图片字段。
+type imageRequest struct {
+	endpoint string
+	headers  map[string]string
+	files    []formFile
+	fields   map[string]string
+	jsonKeys []string
+}
+
+// serviceCodes 为服务的错误码前缀（如 "E50" 对应 E501-E503）与用于提示的服务名称：
+// 1 为构建请求失败，2 为请求或响应失败，3 为结果无效。
+type serviceCodes struct {
+	prefix string
+	name   string
+}
+
+func (s serviceCodes) code(n int) string {
+	return fmt.Sprintf("%s%d", s.prefix, n)
+}
+
+// postImageForm 以 multipart 表单 POST 请求，返回响应中的图像：响应为 JSON 时解码 jsonKeys 中的 base64 图片，
+// 否则直接返回响应体。
+func postImageForm(ctx context.Context, client *http.Client, r imageRequest, codes serviceCodes) ([]byte, error) {
+	body, contentType, err := buildImageForm(r.files, r.fields)
+	if err != nil {
+		return nil, apperror.New(codes.code(1), "构建请求失败", err.Error(), err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, body)
+	if err != nil {
+		return nil, apperror.New(codes.code(1), "创建请求失败", err.Error(), err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, apperror.New(codes.code(2), codes.name+" API 请求失败", err.Error(), err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperror.New(codes.code(2), "读取响应失败", err.Error(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperror.New(codes.code(2), fmt.Sprintf("API 返回错误状态码: %d", resp.StatusCode), truncateBody(data), nil)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return decodeImageJSON(data, r.jsonKeys, codes)
+	}
+	if len(data) == 0 {
+		return nil, apperror.New(codes.code(3), "API 未返回图片", "", nil)
+	}
+	return data, nil
+}
+
+// buildImageForm 按固定顺序写入文件与表单字段，便于服务端日志与测试比对。
+func buildImageForm(files []formFile, fields map[string]string) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.filename)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(file.data); err != nil {
+			return nil, "", err
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := writer.WriteField(key, fields[key]); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// decodeImageJSON 取 keys 中第一个非空字段，支持纯 base64 与 data URL 两种写法。
+func decodeImageJSON(data []byte, keys []string, codes serviceCodes) ([]byte, error) {
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, apperror.New(codes.code(2), "解析响应失败", err.Error(), err)
+	}
+	encoded := ""
+	for _, key := range keys {
+		if value, ok := result[key].(string); ok && value != "" {
+			encoded = value
+			break
+		}
+	}
+	if encoded == "" {
+		return nil, apperror.New(codes.code(3), "API 未返回图片", "", nil)
+	}
+	if idx := strings.Index(encoded, ";base64,"); idx >= 0 {
+		encoded = encoded[idx+len(";base64,"):]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, apperror.New(codes.code(3), "图片数据解码失败", err.Error(), err)
+	}
+	return decoded, nil
+}
+
+func truncateBody(data []byte) string {
+	const limit = 512
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// formRequest 为测试服务端收到的一次 multipart 请求。
+type formRequest struct {
+	header http.Header
+	files  map[string][]byte
+	fields map[string]string
+}
+
+// formServer 模拟图像服务：记录每次 multipart 请求并以 respond 返回响应。
+func formServer(t *testing.T, respond func(w http.ResponseWriter)) (*httptest.Server, *[]formRequest) {
+	t.Helper()
+	var requests []formRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse multipart: %v", err)
+		}
+		req := formRequest{header: r.Header, files: map[string][]byte{}, fields: map[string]string{}}
+		for key, values := range r.MultipartForm.Value {
+			req.fields[key] = values[0]
+		}
+		for field, files := range r.MultipartForm.File {
+			f, err := files[0].Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.files[field], _ = io.ReadAll(f)
+			f.Close()
+		}
+		requests = append(requests, req)
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func respondBytes(contentType string, body []byte) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+func respondJSON(value any) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(value)
+	}
+}
+
+func errorCode(err error) string {
+	if appErr, ok := err.(*apperror.AppError); ok {
+		return appErr.Code
+	}
+	return ""
+}
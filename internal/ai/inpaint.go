@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+const (
+	defaultInpaintTimeout    = 180 * time.Second
+	defaultStabilityEraseURL = "https://api.stability.ai/v2beta/stable-image/edit/erase"
+)
+
+// 支持的图像修复服务提供方：stability 为 Stability AI 擦除接口，http 为通用 multipart 接口。
+const (
+	InpaintProviderStability = "stability"
+	InpaintProviderHTTP      = "http"
+)
+
+// InpaintClient 图像修复服务客户端
+type InpaintClient struct {
+	provider   string
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// IsInpaintProvider 判断提供方是否支持图像修复
+func IsInpaintProvider(provider string) bool {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case InpaintProviderStability, InpaintProviderHTTP:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInpaintClient 创建图像修复客户端
+func NewInpaintClient(provider, endpoint, apiKey string) (*InpaintClient, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	switch provider {
+	case InpaintProviderStability:
+		if apiKey == "" {
+			return nil, apperror.ConfigError("Stability AI API Key 未配置", nil)
+		}
+		if endpoint == "" {
+			endpoint = defaultStabilityEraseURL
+		}
+	case InpaintProviderHTTP:
+		if endpoint == "" {
+			return nil, apperror.ConfigError("图像修复服务 endpoint 未配置", nil)
+		}
+	default:
+		return nil, apperror.ConfigError("不支持的图像修复服务提供方: "+provider, nil)
+	}
+	return &InpaintClient{
+		provider: provider,
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		httpClient: &http.Client{
+			Timeout: defaultInpaintTimeout,
+		},
+	}, nil
+}
+
+// Inpaint 上传 PNG 图像与遮罩（白色为需要修复的区域），返回修复后的图像
+func (c *InpaintClient) Inpaint(ctx context.Context, pngData, mask []byte) ([]byte, error) {
+	r := imageRequest{
+		endpoint: c.endpoint,
+		headers:  map[string]string{"Accept": "image/png, application/json"},
+		files: []formFile{
+			{field: "image", filename: "image.png", data: pngData},
+			{field: "mask", filename: "mask.png", data: mask},
+		},
+		jsonKeys: []string{"image"},
+	}
+	if c.provider == InpaintProviderStability {
+		r.headers["Accept"] = "image/*"
+		r.fields = map[string]string{"output_format": "png"}
+	}
+	if c.apiKey != "" {
+		r.headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	return postImageForm(ctx, c.httpClient, r, serviceCodes{prefix: "E70", name: "图像修复"})
+}
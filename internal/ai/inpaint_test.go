@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestInpaintSendsImageAndMask(t *testing.T) {
+	server, requests := formServer(t, respondBytes("image/png", []byte("fixed")))
+	for _, provider := range []string{"http", "stability"} {
+		client, err := NewInpaintClient(provider, server.URL, "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := client.Inpaint(context.Background(), []byte("photo"), []byte("mask"))
+		if err != nil || string(got) != "fixed" {
+			t.Fatalf("%s: result = %q, %v", provider, got, err)
+		}
+	}
+	for i, req := range *requests {
+		if string(req.files["image"]) != "photo" || string(req.files["mask"]) != "mask" {
+			t.Errorf("request %d files = %v", i, req.files)
+		}
+	}
+	if (*requests)[1].fields["output_format"] != "png" || (*requests)[0].fields["output_format"] != "" {
+		t.Errorf("output_format fields = %v / %v", (*requests)[0].fields, (*requests)[1].fields)
+	}
+}
+
+func TestInpaintErrors(t *testing.T) {
+	for _, tc := range []struct {
+		respond func(w http.ResponseWriter)
+		code    string
+	}{
+		{func(w http.ResponseWriter) { http.Error(w, "bad mask", http.StatusBadRequest) }, "E702"},
+		{respondJSON(map[string]string{}), "E703"},
+	} {
+		server, _ := formServer(t, tc.respond)
+		client, err := NewInpaintClient("http", server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Inpaint(context.Background(), []byte("photo"), []byte("mask")); errorCode(err) != tc.code {
+			t.Errorf("err = %v, want %s", err, tc.code)
+		}
+	}
+}
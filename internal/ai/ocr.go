@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"math"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/kiry163/image-cli/pkg/apperror"
@@ -20,6 +23,23 @@ type OCROptions struct {
 	Mode string
 }
 
+// OCRBox 为 grounding 模式识别出的文本框，坐标已归一化到 0-1。
+type OCRBox struct {
+	Text string
+	X1   float64
+	Y1   float64
+	X2   float64
+	Y2   float64
+}
+
+// grounding 输出形如 <|ref|>文本<|/ref|><|det|>[[x1, y1, x2, y2]]<|/det|>，坐标范围 0-999。
+var (
+	groundingPattern = regexp.MustCompile(`(?s)<\|ref\|>(.*?)<\|/ref\|>\s*<\|det\|>(.*?)<\|/det\|>`)
+	boxPattern       = regexp.MustCompile(`\[\s*(-?[\d.]+)\s*,\s*(-?[\d.]+)\s*,\s*(-?[\d.]+)\s*,\s*(-?[\d.]+)\s*\]`)
+)
+
+const groundingScale = 999
+
 func NewOCRClient(apiKey, baseURL, model string) (*OCRClient, error) {
 	if apiKey == "" {
 		return nil, apperror.ConfigError("OCR API Key 未配置", nil)
@@ -45,13 +65,45 @@ func (c *OCRClient) Recognize(ctx context.Context, imagePath string, opts OCROpt
 	if err != nil {
 		return "", apperror.InvalidInput("无法读取图片文件", err)
 	}
+	return c.complete(ctx, imageData, detectMimeType(imagePath), buildOCRPrompt(opts.Mode))
+}
+
+// Locate 以 grounding 模式识别 PNG 图像，返回每段文本及其位置。
+func (c *OCRClient) Locate(ctx context.Context, pngData []byte) ([]OCRBox, error) {
+	content, err := c.complete(ctx, pngData, "image/png", buildOCRPrompt("text"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseGroundingBoxes(content), nil
+}
 
+// ParseGroundingBoxes 解析 grounding 输出中的文本框，忽略无法解析的片段。
+func ParseGroundingBoxes(content string) []OCRBox {
+	boxes := []OCRBox{}
+	for _, match := range groundingPattern.FindAllStringSubmatch(content, -1) {
+		text := strings.TrimSpace(match[1])
+		for _, coords := range boxPattern.FindAllStringSubmatch(match[2], -1) {
+			var values [4]float64
+			for i := range values {
+				value, err := strconv.ParseFloat(coords[i+1], 64)
+				if err != nil {
+					value = 0
+				}
+				values[i] = math.Min(math.Max(value/groundingScale, 0), 1)
+			}
+			if values[2] <= values[0] || values[3] <= values[1] {
+				continue
+			}
+			boxes = append(boxes, OCRBox{Text: text, X1: values[0], Y1: values[1], X2: values[2], Y2: values[3]})
+		}
+	}
+	return boxes
+}
+
+func (c *OCRClient) complete(ctx context.Context, imageData []byte, mimeType, systemPrompt string) (string, error) {
 	base64Image := base64.StdEncoding.EncodeToString(imageData)
-	mimeType := detectMimeType(imagePath)
 	imageURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64Image)
 
-	systemPrompt := buildOCRPrompt(opts.Mode)
-
 	req := openai.ChatCompletionRequest{
 		Model: c.model,
 		Messages: []openai.ChatCompletionMessage{
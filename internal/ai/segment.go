@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+const (
+	defaultSegmentTimeout = 120 * time.Second
+	defaultRemoveBgURL    = "https://api.remove.bg/v1.0/removebg"
+)
+
+// 支持的抠图服务提供方：removebg 为 remove.bg API，http 为通用 multipart 接口。
+const (
+	SegmentProviderRemoveBg = "removebg"
+	SegmentProviderHTTP     = "http"
+)
+
+// SegmentClient 抠图服务客户端
+type SegmentClient struct {
+	provider   string
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// SegmentOptions 抠图选项
+type SegmentOptions struct {
+	MaskOnly bool
+	Matte    bool
+}
+
+// IsSegmentProvider 判断提供方是否支持抠图
+func IsSegmentProvider(provider string) bool {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case SegmentProviderRemoveBg, SegmentProviderHTTP:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewSegmentClient 创建抠图客户端
+func NewSegmentClient(provider, endpoint, apiKey string) (*SegmentClient, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	switch provider {
+	case SegmentProviderRemoveBg:
+		if apiKey == "" {
+			return nil, apperror.ConfigError("remove.bg API Key 未配置", nil)
+		}
+		if endpoint == "" {
+			endpoint = defaultRemoveBgURL
+		}
+	case SegmentProviderHTTP:
+		if endpoint == "" {
+			return nil, apperror.ConfigError("抠图服务 endpoint 未配置", nil)
+		}
+	default:
+		return nil, apperror.ConfigError("不支持的抠图服务提供方: "+provider, nil)
+	}
+	return &SegmentClient{
+		provider: provider,
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		httpClient: &http.Client{
+			Timeout: defaultSegmentTimeout,
+		},
+	}, nil
+}
+
+// RemoveBackground 上传 PNG 图像并返回服务结果：MaskOnly 时为灰度遮罩，否则为带透明通道的 PNG
+func (c *SegmentClient) RemoveBackground(ctx context.Context, pngData []byte, opts SegmentOptions) ([]byte, error) {
+	r := imageRequest{
+		endpoint: c.endpoint,
+		headers:  map[string]string{"Accept": "image/png, application/json"},
+		files:    []formFile{{field: "image", filename: "image.png", data: pngData}},
+		fields: map[string]string{
+			"mask_only": strconv.FormatBool(opts.MaskOnly),
+			"matte":     strconv.FormatBool(opts.Matte),
+		},
+		jsonKeys: []string{"image", "mask"},
+	}
+	if opts.MaskOnly {
+		r.jsonKeys = []string{"mask", "image"}
+	}
+	if c.provider == SegmentProviderRemoveBg {
+		r.headers["X-Api-Key"] = c.apiKey
+		r.files[0].field = "image_file"
+		channels := "rgba"
+		if opts.MaskOnly {
+			channels = "alpha"
+		}
+		r.fields = map[string]string{
+			"size":             "auto",
+			"format":           "png",
+			"channels":         channels,
+			"semitransparency": strconv.FormatBool(opts.Matte),
+		}
+	} else if c.apiKey != "" {
+		r.headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	return postImageForm(ctx, c.httpClient, r, serviceCodes{prefix: "E50", name: "抠图"})
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+func TestSegmentHTTPProviderRawPNG(t *testing.T) {
+	server, requests := formServer(t, respondBytes("image/png", []byte("cutout")))
+	client, err := NewSegmentClient("http", server.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.RemoveBackground(context.Background(), []byte("input"), SegmentOptions{MaskOnly: true, Matte: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "cutout" {
+		t.Errorf("result = %q, want raw response body", got)
+	}
+	req := (*requests)[0]
+	if len(req.files) != 1 || string(req.files["image"]) != "input" {
+		t.Errorf("files = %v, want a single image field", req.files)
+	}
+	if req.fields["mask_only"] != "true" || req.fields["matte"] != "true" {
+		t.Errorf("fields = %v, want mask_only and matte true", req.fields)
+	}
+	if req.header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q", req.header.Get("Authorization"))
+	}
+}
+
+func TestSegmentHTTPProviderJSON(t *testing.T) {
+	image := base64.StdEncoding.EncodeToString([]byte("rgba"))
+	mask := base64.StdEncoding.EncodeToString([]byte("alpha"))
+	server, _ := formServer(t, respondJSON(map[string]string{"image": "data:image/png;base64," + image, "mask": mask}))
+	client, err := NewSegmentClient("http", server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		maskOnly bool
+		want     string
+	}{{false, "rgba"}, {true, "alpha"}} {
+		got, err := client.RemoveBackground(context.Background(), []byte("input"), SegmentOptions{MaskOnly: tc.maskOnly})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("maskOnly=%v: result = %q, want %q", tc.maskOnly, got, tc.want)
+		}
+	}
+}
+
+func TestSegmentRemoveBgProviderForm(t *testing.T) {
+	server, requests := formServer(t, respondBytes("image/png", []byte("cutout")))
+	client, err := NewSegmentClient("removebg", server.URL, "rb-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RemoveBackground(context.Background(), []byte("input"), SegmentOptions{Matte: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RemoveBackground(context.Background(), []byte("input"), SegmentOptions{MaskOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	matte, maskOnly := (*requests)[0], (*requests)[1]
+	if string(matte.files["image_file"]) != "input" || matte.header.Get("X-Api-Key") != "rb-key" || matte.header.Get("Authorization") != "" {
+		t.Errorf("files = %v, headers = %v", matte.files, matte.header)
+	}
+	if matte.fields["channels"] != "rgba" || matte.fields["semitransparency"] != "true" || matte.fields["format"] != "png" {
+		t.Errorf("matte fields = %v", matte.fields)
+	}
+	if maskOnly.fields["channels"] != "alpha" || maskOnly.fields["semitransparency"] != "false" {
+		t.Errorf("mask-only fields = %v", maskOnly.fields)
+	}
+}
+
+func TestSegmentErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		respond func(w http.ResponseWriter)
+		code    string
+	}{
+		{"status", func(w http.ResponseWriter) { http.Error(w, "quota exceeded", http.StatusPaymentRequired) }, "E502"},
+		{"bad json", respondBytes("application/json", []byte("{")), "E502"},
+		{"empty body", respondBytes("image/png", nil), "E503"},
+		{"json without image", respondJSON(map[string]string{"status": "ok"}), "E503"},
+		{"bad base64", respondJSON(map[string]string{"image": "!!"}), "E503"},
+	} {
+		server, _ := formServer(t, tc.respond)
+		client, err := NewSegmentClient("http", server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.RemoveBackground(context.Background(), []byte("input"), SegmentOptions{})
+		if errorCode(err) != tc.code {
+			t.Errorf("%s: err = %v, want %s", tc.name, err, tc.code)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+func TestUpscaleProviderForms(t *testing.T) {
+	server, requests := formServer(t, respondBytes("image/png", []byte("big")))
+	for _, provider := range []string{"http", "stability"} {
+		client, err := NewUpscaleClient(provider, server.URL, "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := client.Upscale(context.Background(), []byte("small"), UpscaleOptions{Scale: 2, Denoise: true})
+		if err != nil || string(got) != "big" {
+			t.Fatalf("%s: result = %q, %v", provider, got, err)
+		}
+	}
+	httpReq, stability := (*requests)[0], (*requests)[1]
+	if string(httpReq.files["image"]) != "small" || httpReq.fields["scale"] != "2" || httpReq.fields["denoise"] != "true" || httpReq.fields["sharpen"] != "false" {
+		t.Errorf("http request = %v %v", httpReq.files, httpReq.fields)
+	}
+	if stability.fields["output_format"] != "png" || stability.fields["scale"] != "" || stability.header.Get("Accept") != "image/*" {
+		t.Errorf("stability request = %v %v", stability.fields, stability.header)
+	}
+	if stability.header.Get("Authorization") != "Bearer key" {
+		t.Errorf("Authorization = %q", stability.header.Get("Authorization"))
+	}
+}
+
+func TestUpscaleResponses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		respond func(w http.ResponseWriter)
+		want    string
+		code    string
+	}{
+		{"json", respondJSON(map[string]string{"image": base64.StdEncoding.EncodeToString([]byte("big"))}), "big", ""},
+		{"status", func(w http.ResponseWriter) { http.Error(w, "busy", http.StatusServiceUnavailable) }, "", "E602"},
+		{"empty", respondBytes("image/png", nil), "", "E603"},
+	} {
+		server, _ := formServer(t, tc.respond)
+		client, err := NewUpscaleClient("http", server.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := client.Upscale(context.Background(), []byte("small"), UpscaleOptions{Scale: 2})
+		if string(got) != tc.want || errorCode(err) != tc.code {
+			t.Errorf("%s: result = %q, err = %v; want %q, %s", tc.name, got, err, tc.want, tc.code)
+		}
+	}
+}
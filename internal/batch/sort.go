@@ -0,0 +1,54 @@
+package batch
+
+import (
+	"sort"
+	"strings"
+)
+
+// NaturalSort 按自然顺序排序文件名，使 page2 排在 page10 之前。
+func NaturalSort(files []string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return NaturalLess(files[i], files[j])
+	})
+}
+
+func NaturalLess(a, b string) bool {
+	a = strings.ToLower(a)
+	b = strings.ToLower(b)
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := splitDigits(a)
+			numB, restB := splitDigits(b)
+			trimA := strings.TrimLeft(numA, "0")
+			trimB := strings.TrimLeft(numB, "0")
+			if len(trimA) != len(trimB) {
+				return len(trimA) < len(trimB)
+			}
+			if trimA != trimB {
+				return trimA < trimB
+			}
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func splitDigits(value string) (string, string) {
+	i := 0
+	for i < len(value) && isDigit(value[i]) {
+		i++
+	}
+	return value[:i], value[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
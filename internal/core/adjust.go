@@ -0,0 +1,165 @@
+package core
+
+import (
+	"image"
+	"math"
+	"os"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// AdjustOptions 中亮度、对比度、饱和度与 gamma 为倍数，1 表示不调整；Hue 为色相旋转角度。
+type AdjustOptions struct {
+	Brightness       float64
+	Contrast         float64
+	Saturation       float64
+	Gamma            float64
+	Hue              float64
+	AutoLevel        bool
+	AutoWhiteBalance bool
+	Quality          int
+	Conflict         string
+}
+
+// Adjust 调整图像色调，动图逐帧处理。
+func Adjust(inputPath, outputArg string, opts AdjustOptions) (string, error) {
+	transform, err := AdjustTransform(opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, transform)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		img, err := decodeRaster(buf)
+		if err != nil {
+			return "", err
+		}
+		img, err = transform(img)
+		if err != nil {
+			return "", err
+		}
+		newImage, err = encodeRaster(img, outType, opts.Quality)
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+// AdjustTransform 校验参数并返回逐帧调整函数。顺序依次为自动白平衡、自动色阶、
+// 亮度、对比度、gamma、饱和度与色相。
+func AdjustTransform(opts AdjustOptions) (frameTransform, error) {
+	for _, value := range []float64{opts.Brightness, opts.Contrast, opts.Gamma} {
+		if !(value > 0) || math.IsInf(value, 0) {
+			return nil, apperror.InvalidArgument("亮度、对比度与 gamma 必须为正数", nil)
+		}
+	}
+	if !(opts.Saturation >= 0) || math.IsInf(opts.Saturation, 0) {
+		return nil, apperror.InvalidArgument("饱和度不能为负数", nil)
+	}
+	brightness, contrast, saturation, gamma := opts.Brightness, opts.Contrast, opts.Saturation, opts.Gamma
+	hue := math.Mod(opts.Hue, 360)
+	tonal := brightness != 1 || contrast != 1 || gamma != 1
+	color := saturation != 1 || hue != 0
+	if !tonal && !color && !opts.AutoLevel && !opts.AutoWhiteBalance {
+		return nil, apperror.InvalidArgument("至少需要指定一项调整", nil)
+	}
+	var lut [256]uint8
+	for v := range lut {
+		value := float64(v) / 255 * brightness
+		value = (value-0.5)*contrast + 0.5
+		value = math.Pow(math.Max(value, 0), 1/gamma)
+		lut[v] = clampByte(value * 255)
+	}
+	matrix := colorMatrix(saturation, hue)
+	return func(frame *image.NRGBA) (*image.NRGBA, error) {
+		dst := cloneNRGBA(frame)
+		if opts.AutoWhiteBalance {
+			grayWorldBalance(dst)
+		}
+		if opts.AutoLevel {
+			autoLevel(dst)
+		}
+		if tonal {
+			applyChannelLUT(dst, [3][256]uint8{lut, lut, lut})
+		}
+		if color {
+			applyColorMatrix(dst, matrix)
+		}
+		return dst, nil
+	}, nil
+}
+
+// colorMatrix 组合饱和度与色相旋转矩阵，系数与 SVG feColorMatrix 一致。
+func colorMatrix(saturation, hue float64) [9]float64 {
+	const lr, lg, lb = 0.213, 0.715, 0.072
+	sat := [9]float64{
+		lr*(1-saturation) + saturation, lg * (1 - saturation), lb * (1 - saturation),
+		lr * (1 - saturation), lg*(1-saturation) + saturation, lb * (1 - saturation),
+		lr * (1 - saturation), lg * (1 - saturation), lb*(1-saturation) + saturation,
+	}
+	rad := hue * math.Pi / 180
+	c, s := math.Cos(rad), math.Sin(rad)
+	rot := [9]float64{
+		lr + c*(1-lr) + s*(-lr), lg + c*(-lg) + s*(-lg), lb + c*(-lb) + s*(1-lb),
+		lr + c*(-lr) + s*0.143, lg + c*(1-lg) + s*0.140, lb + c*(-lb) + s*(-0.283),
+		lr + c*(-lr) + s*(-(1 - lr)), lg + c*(-lg) + s*lg, lb + c*(1-lb) + s*lb,
+	}
+	var out [9]float64
+	for r := 0; r < 3; r++ {
+		for col := 0; col < 3; col++ {
+			for k := 0; k < 3; k++ {
+				out[r*3+col] += rot[r*3+k] * sat[k*3+col]
+			}
+		}
+	}
+	return out
+}
+
+func applyColorMatrix(img *image.NRGBA, m [9]float64) {
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			r, g, b := float64(row[i]), float64(row[i+1]), float64(row[i+2])
+			row[i] = clampByte(m[0]*r + m[1]*g + m[2]*b)
+			row[i+1] = clampByte(m[3]*r + m[4]*g + m[5]*b)
+			row[i+2] = clampByte(m[6]*r + m[7]*g + m[8]*b)
+		}
+	}
+}
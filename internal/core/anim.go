@@ -0,0 +1,455 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// AnimationInfo 的 LoopCount 为播放次数，0 表示无限循环。
+type AnimationInfo struct {
+	Frames    int
+	Duration  time.Duration
+	LoopCount int
+}
+
+// animation 保存合成后的完整画布帧（原始帧区域与处置方式在编码 GIF 时重新推导），延迟单位为毫秒，LoopCount 为播放次数 (0 表示无限循环)，
+// Colors 限制 GIF 调色板大小 (0 表示 256)。
+type animation struct {
+	Width     int
+	Height    int
+	Frames    []*image.NRGBA
+	Delays    []int
+	LoopCount int
+	Colors    int
+}
+
+type frameTransform func(*image.NRGBA) (*image.NRGBA, error)
+
+func AnimationMetadata(buf []byte) (AnimationInfo, bool) {
+	switch {
+	case isGIF(buf):
+		g, err := gif.DecodeAll(bytes.NewReader(buf))
+		if err != nil || len(g.Image) < 2 {
+			return AnimationInfo{}, false
+		}
+		total := 0
+		for _, delay := range g.Delay {
+			total += delay * 10
+		}
+		return AnimationInfo{Frames: len(g.Image), Duration: time.Duration(total) * time.Millisecond, LoopCount: gifLoopToIterations(g.LoopCount)}, true
+	case isWebP(buf):
+		info, ok := parseWebPAnimation(buf)
+		if !ok || info.Frames < 2 {
+			return AnimationInfo{}, false
+		}
+		return info, true
+	default:
+		return AnimationInfo{}, false
+	}
+}
+
+func isAnimated(buf []byte) bool {
+	_, ok := AnimationMetadata(buf)
+	return ok
+}
+
+func isAnimatedFormat(format string) bool {
+	format = NormalizeFormat(format)
+	return format == "gif" || format == "webp"
+}
+
+func isGIF(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte("GIF87a")) || bytes.HasPrefix(buf, []byte("GIF89a"))
+}
+
+func isWebP(buf []byte) bool {
+	return len(buf) >= 12 && string(buf[0:4]) == "RIFF" && string(buf[8:12]) == "WEBP"
+}
+
+func parseWebPAnimation(buf []byte) (AnimationInfo, bool) {
+	info := AnimationInfo{}
+	animated := false
+	totalMs := 0
+	for offset := 12; offset+8 <= len(buf); {
+		fourCC := string(buf[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(buf[offset+4 : offset+8]))
+		start := offset + 8
+		end := start + size
+		if end > len(buf) {
+			return AnimationInfo{}, false
+		}
+		payload := buf[start:end]
+		switch fourCC {
+		case "VP8X":
+			if len(payload) > 0 && payload[0]&0x02 != 0 {
+				animated = true
+			}
+		case "ANIM":
+			if len(payload) >= 6 {
+				info.LoopCount = int(binary.LittleEndian.Uint16(payload[4:6]))
+			}
+		case "ANMF":
+			if len(payload) >= 16 {
+				info.Frames++
+				totalMs += int(payload[12]) | int(payload[13])<<8 | int(payload[14])<<16
+			}
+		}
+		offset = end + size%2
+	}
+	if !animated {
+		return AnimationInfo{}, false
+	}
+	info.Duration = time.Duration(totalMs) * time.Millisecond
+	return info, true
+}
+
+func webPFrameDelays(buf []byte) []int {
+	delays := []int{}
+	for offset := 12; offset+8 <= len(buf); {
+		size := int(binary.LittleEndian.Uint32(buf[offset+4 : offset+8]))
+		start := offset + 8
+		end := start + size
+		if end > len(buf) {
+			break
+		}
+		if string(buf[offset:offset+4]) == "ANMF" && size >= 16 {
+			payload := buf[start:end]
+			delays = append(delays, int(payload[12])|int(payload[13])<<8|int(payload[14])<<16)
+		}
+		offset = end + size%2
+	}
+	return delays
+}
+
+func decodeAnimation(buf []byte) (*animation, error) {
+	if isGIF(buf) {
+		return decodeGIFAnimation(buf)
+	}
+	if isWebP(buf) {
+		return decodeWebPAnimation(buf)
+	}
+	return nil, apperror.UnsupportedFormat("仅支持 GIF 与 WebP 动图", nil)
+}
+
+func decodeGIFAnimation(buf []byte) (*animation, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(buf))
+	if err != nil {
+		return nil, apperror.InvalidInput("无法解析 GIF 动图", err)
+	}
+	width, height := g.Config.Width, g.Config.Height
+	if width == 0 || height == 0 {
+		for _, frame := range g.Image {
+			if frame.Rect.Max.X > width {
+				width = frame.Rect.Max.X
+			}
+			if frame.Rect.Max.Y > height {
+				height = frame.Rect.Max.Y
+			}
+		}
+	}
+	anim := &animation{Width: width, Height: height, LoopCount: gifLoopToIterations(g.LoopCount)}
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneNRGBA(canvas)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		anim.Frames = append(anim.Frames, cloneNRGBA(canvas))
+		delay := 0
+		if i < len(g.Delay) {
+			delay = g.Delay[i] * 10
+		}
+		anim.Delays = append(anim.Delays, delay)
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
+
+func decodeWebPAnimation(buf []byte) (*animation, error) {
+	info, ok := parseWebPAnimation(buf)
+	if !ok {
+		return nil, apperror.InvalidInput("无法解析 WebP 动图", nil)
+	}
+	cmdPath, ok := ImageMagickCommand()
+	if !ok {
+		return nil, apperror.UnsupportedFormat("WebP 动图需要安装 ImageMagick", nil)
+	}
+	tempDir, err := os.MkdirTemp("", "image-cli-anim-")
+	if err != nil {
+		return nil, apperror.ConfigError("无法创建临时目录", err)
+	}
+	defer os.RemoveAll(tempDir)
+	inputPath := filepath.Join(tempDir, "input.webp")
+	if err := os.WriteFile(inputPath, buf, 0o644); err != nil {
+		return nil, apperror.ConfigError("无法写入临时文件", err)
+	}
+	output, err := exec.Command(cmdPath, inputPath, "-coalesce", filepath.Join(tempDir, "frame_%05d.png")).CombinedOutput()
+	if err != nil {
+		return nil, apperror.InvalidInput("WebP 动图解码失败: "+strings.TrimSpace(string(output)), err)
+	}
+	paths, err := filepath.Glob(filepath.Join(tempDir, "frame_*.png"))
+	if err != nil || len(paths) == 0 {
+		return nil, apperror.InvalidInput("WebP 动图解码失败", err)
+	}
+	sort.Strings(paths)
+	delays := webPFrameDelays(buf)
+	anim := &animation{LoopCount: info.LoopCount}
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, apperror.ConfigError("无法读取临时文件", err)
+		}
+		frame, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, apperror.InvalidInput("WebP 动图解码失败", err)
+		}
+		anim.Frames = append(anim.Frames, toNRGBA(frame))
+		delay := 100
+		if i < len(delays) {
+			delay = delays[i]
+		}
+		anim.Delays = append(anim.Delays, delay)
+	}
+	anim.Width = anim.Frames[0].Rect.Dx()
+	anim.Height = anim.Frames[0].Rect.Dy()
+	return anim, nil
+}
+
+func encodeAnimation(anim *animation, format string, quality int) ([]byte, error) {
+	switch NormalizeFormat(format) {
+	case "gif":
+		return encodeGIFAnimation(anim)
+	case "webp":
+		return encodeWebPAnimation(anim, quality)
+	default:
+		return nil, apperror.UnsupportedFormat("动图仅支持输出 GIF 与 WebP", nil)
+	}
+}
+
+func encodeGIFAnimation(anim *animation) ([]byte, error) {
+	transparent := false
+	for _, frame := range anim.Frames {
+		if hasTransparency(frame) {
+			transparent = true
+			break
+		}
+	}
+	maxColors := 256
+	if anim.Colors > 0 && anim.Colors < maxColors {
+		maxColors = anim.Colors
+	}
+	if transparent && maxColors == 256 {
+		maxColors = 255
+	}
+	palette := medianCutPalette(anim.Frames, maxColors)
+	transparentIndex := -1
+	if transparent {
+		transparentIndex = len(palette)
+		palette = append(palette, color.NRGBA{})
+	}
+	out := &gif.GIF{
+		LoopCount: iterationsToGIFLoop(anim.LoopCount),
+		Config:    image.Config{ColorModel: palette, Width: anim.Width, Height: anim.Height},
+	}
+	frames := make([]*image.Paletted, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		frames[i] = toPaletted(frame, palette, transparentIndex)
+	}
+	rects, disposal := gifFrameRects(frames, transparentIndex)
+	for i, frame := range frames {
+		out.Image = append(out.Image, frame.SubImage(rects[i]).(*image.Paletted))
+		out.Delay = append(out.Delay, (anim.Delays[i]+5)/10)
+		out.Disposal = append(out.Disposal, disposal[i])
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, out); err != nil {
+		return nil, apperror.ConfigError("GIF 编码失败", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gifFrameRects 由合成后的完整帧重新推导每帧的最小更新区域与处置方式：
+// 下一帧只需覆盖与当前画面不同的像素；若有像素要从不透明变为透明，上一帧改用 DisposalBackground，并扩大其区域以覆盖这些像素。
+// 首帧含透明像素时，末帧输出整幅画布并清除背景，避免循环播放时残留上一轮的内容。
+func gifFrameRects(frames []*image.Paletted, transparentIndex int) ([]image.Rectangle, []byte) {
+	rects := make([]image.Rectangle, len(frames))
+	disposal := make([]byte, len(frames))
+	if len(frames) == 0 {
+		return rects, disposal
+	}
+	bounds := frames[0].Rect
+	rects[0] = bounds
+	disposal[0] = gif.DisposalNone
+	canvas := make([]uint8, len(frames[0].Pix))
+	copy(canvas, frames[0].Pix)
+	for i := 1; i < len(frames); i++ {
+		pix := frames[i].Pix
+		if transparentIndex >= 0 {
+			// DisposalBackground 只清除上一帧自身的区域，因此要把所有变为透明的像素并入该区域。
+			cleared := image.Rectangle{}
+			for j, index := range pix {
+				if int(index) == transparentIndex && int(canvas[j]) != transparentIndex {
+					x, y := j%bounds.Dx(), j/bounds.Dx()
+					cleared = cleared.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+			if !cleared.Empty() {
+				disposal[i-1] = gif.DisposalBackground
+				rects[i-1] = rects[i-1].Union(cleared)
+			}
+		}
+		if disposal[i-1] == gif.DisposalBackground {
+			prev := rects[i-1]
+			for y := prev.Min.Y; y < prev.Max.Y; y++ {
+				for x := prev.Min.X; x < prev.Max.X; x++ {
+					canvas[y*bounds.Dx()+x] = uint8(transparentIndex)
+				}
+			}
+		}
+		changed := image.Rectangle{}
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				j := y*bounds.Dx() + x
+				if pix[j] != canvas[j] {
+					changed = changed.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		if changed.Empty() {
+			changed = image.Rect(0, 0, 1, 1)
+		}
+		rects[i] = changed
+		disposal[i] = gif.DisposalNone
+		copy(canvas, pix)
+	}
+	if transparentIndex >= 0 && len(frames) > 1 {
+		for _, index := range frames[0].Pix {
+			if int(index) == transparentIndex {
+				last := len(frames) - 1
+				rects[last] = bounds
+				disposal[last] = gif.DisposalBackground
+				break
+			}
+		}
+	}
+	return rects, disposal
+}
+
+func encodeWebPAnimation(anim *animation, quality int) ([]byte, error) {
+	cmdPath, ok := ImageMagickCommand()
+	if !ok {
+		return nil, apperror.UnsupportedFormat("WebP 动图需要安装 ImageMagick", nil)
+	}
+	tempDir, err := os.MkdirTemp("", "image-cli-anim-")
+	if err != nil {
+		return nil, apperror.ConfigError("无法创建临时目录", err)
+	}
+	defer os.RemoveAll(tempDir)
+	args := []string{}
+	for i, frame := range anim.Frames {
+		framePath := filepath.Join(tempDir, fmt.Sprintf("frame_%05d.png", i))
+		data, err := encodePNG(frame)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(framePath, data, 0o644); err != nil {
+			return nil, apperror.ConfigError("无法写入临时文件", err)
+		}
+		args = append(args, "-delay", fmt.Sprintf("%dx1000", anim.Delays[i]), framePath)
+	}
+	args = append(args, "-loop", fmt.Sprintf("%d", anim.LoopCount))
+	if quality > 0 {
+		args = append(args, "-quality", fmt.Sprintf("%d", quality))
+	}
+	outPath := filepath.Join(tempDir, "output.webp")
+	args = append(args, outPath)
+	output, err := exec.Command(cmdPath, args...).CombinedOutput()
+	if err != nil {
+		return nil, apperror.ConfigError("WebP 动图编码失败: "+strings.TrimSpace(string(output)), err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, apperror.ConfigError("无法读取临时文件", err)
+	}
+	return data, nil
+}
+
+func processAnimation(buf []byte, outFormat string, quality int, transform frameTransform) ([]byte, error) {
+	anim, err := decodeAnimation(buf)
+	if err != nil {
+		return nil, err
+	}
+	if transform != nil {
+		for i, frame := range anim.Frames {
+			transformed, err := transform(frame)
+			if err != nil {
+				return nil, err
+			}
+			anim.Frames[i] = transformed
+		}
+		anim.Width = anim.Frames[0].Rect.Dx()
+		anim.Height = anim.Frames[0].Rect.Dy()
+	}
+	return encodeAnimation(anim, outFormat, quality)
+}
+
+func hasTransparency(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] < 128 {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneNRGBA(img *image.NRGBA) *image.NRGBA {
+	dst := image.NewNRGBA(img.Rect)
+	copy(dst.Pix, img.Pix)
+	return dst
+}
+
+func gifLoopToIterations(loop int) int {
+	switch {
+	case loop == 0:
+		return 0
+	case loop < 0:
+		return 1
+	default:
+		return loop + 1
+	}
+}
+
+func iterationsToGIFLoop(iterations int) int {
+	switch {
+	case iterations <= 0:
+		return 0
+	case iterations == 1:
+		return -1
+	default:
+		return iterations - 1
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"testing"
+)
+
+// movingSquareAnimation 生成一个方块在背景上移动的合成帧序列。
+func movingSquareAnimation(background color.NRGBA) *animation {
+	anim := &animation{Width: 40, Height: 30}
+	square := color.NRGBA{R: 220, G: 30, B: 30, A: 255}
+	for i := 0; i < 4; i++ {
+		frame := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+		draw.Draw(frame, frame.Rect, &image.Uniform{C: background}, image.Point{}, draw.Src)
+		draw.Draw(frame, image.Rect(4+i*8, 10, 12+i*8, 18), &image.Uniform{C: square}, image.Point{}, draw.Src)
+		anim.Frames = append(anim.Frames, frame)
+		anim.Delays = append(anim.Delays, 100)
+	}
+	return anim
+}
+
+func TestGIFAnimationRoundTripUsesMinimalFrames(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		background color.NRGBA
+	}{
+		{"opaque", color.NRGBA{R: 240, G: 240, B: 240, A: 255}},
+		{"transparent", color.NRGBA{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			anim := movingSquareAnimation(tc.background)
+			data, err := encodeGIFAnimation(anim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := gif.DecodeAll(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			canvas := image.Rect(0, 0, anim.Width, anim.Height)
+			for i := 1; i < len(g.Image)-1; i++ {
+				if g.Image[i].Rect == canvas {
+					t.Errorf("frame %d covers the whole canvas, want only the changed area", i)
+				}
+			}
+			decoded, err := decodeGIFAnimation(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decoded.Frames) != len(anim.Frames) {
+				t.Fatalf("frames = %d, want %d", len(decoded.Frames), len(anim.Frames))
+			}
+			for i, frame := range decoded.Frames {
+				for y := 0; y < anim.Height; y++ {
+					for x := 0; x < anim.Width; x++ {
+						got, want := frame.NRGBAAt(x, y), anim.Frames[i].NRGBAAt(x, y)
+						if want.A == 0 && got.A == 0 {
+							continue
+						}
+						if got != want {
+							t.Fatalf("frame %d pixel (%d,%d) = %v, want %v", i, x, y, got, want)
+						}
+					}
+				}
+				if decoded.Delays[i] != 100 {
+					t.Errorf("frame %d delay = %d, want 100", i, decoded.Delays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGIFFrameRectsClearsPixelsThatBecomeTransparent(t *testing.T) {
+	palette := color.Palette{color.NRGBA{R: 255, A: 255}, color.NRGBA{}}
+	first := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	second := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	for i := range second.Pix {
+		second.Pix[i] = 1
+	}
+	second.Pix[0] = 0
+	rects, disposal := gifFrameRects([]*image.Paletted{first, second}, 1)
+	if disposal[0] != gif.DisposalBackground {
+		t.Fatalf("disposal[0] = %d, want DisposalBackground", disposal[0])
+	}
+	if rects[1] != image.Rect(0, 0, 1, 1) {
+		t.Fatalf("rects[1] = %v, want the single opaque pixel", rects[1])
+	}
+}
+
+func TestGIFAnimationClearsPixelsOutsidePreviousRect(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	anim := &animation{Width: 4, Height: 4}
+	for i := 0; i < 3; i++ {
+		frame := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		draw.Draw(frame, frame.Rect, &image.Uniform{C: red}, image.Point{}, draw.Src)
+		if i >= 1 {
+			frame.SetNRGBA(0, 0, blue)
+		}
+		if i == 2 {
+			frame.SetNRGBA(3, 3, color.NRGBA{})
+		}
+		anim.Frames = append(anim.Frames, frame)
+		anim.Delays = append(anim.Delays, 100)
+	}
+	data, err := encodeGIFAnimation(anim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeGIFAnimation(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, frame := range decoded.Frames {
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				got, want := frame.NRGBAAt(x, y), anim.Frames[i].NRGBAAt(x, y)
+				if want.A == 0 && got.A == 0 {
+					continue
+				}
+				if got != want {
+					t.Fatalf("frame %d pixel (%d,%d) = %v, want %v", i, x, y, got, want)
+				}
+			}
+		}
+	}
+}
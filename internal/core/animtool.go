@@ -0,0 +1,250 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// minFrameDelay 为加速后允许的最小帧延迟 (毫秒)，过小的延迟会被多数播放器当作默认值处理。
+const minFrameDelay = 10
+
+type ExtractFramesOptions struct {
+	Conflict string
+}
+
+type AssembleOptions struct {
+	Format   string
+	Delay    int
+	Loop     int
+	Quality  int
+	Conflict string
+}
+
+type SpeedOptions struct {
+	Factor   float64
+	Conflict string
+}
+
+type OptimizeOptions struct {
+	Colors   int
+	Fuzz     int
+	Conflict string
+}
+
+func ExtractFrames(inputPath, outputDir string, opts ExtractFramesOptions) ([]string, error) {
+	anim, err := readAnimation(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(outputDir, string(os.PathSeparator)) {
+		outputDir += string(os.PathSeparator)
+	}
+	base := filepath.Base(inputPath)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	outputs := make([]string, 0, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		outPath, _, err := ResolveOutput(OutputSpec{
+			InputPath:     fmt.Sprintf("%s_%04d", stem, i+1),
+			OutputArg:     outputDir,
+			DesiredFormat: "png",
+			Conflict:      opts.Conflict,
+		})
+		if err != nil {
+			return outputs, err
+		}
+		data, err := encodePNG(frame)
+		if err != nil {
+			return outputs, err
+		}
+		if err := os.WriteFile(outPath, data, 0o644); err != nil {
+			return outputs, apperror.ConfigError("无法写入输出文件", err)
+		}
+		outputs = append(outputs, outPath)
+	}
+	return outputs, nil
+}
+
+func AssembleAnimation(inputs []string, outputArg string, opts AssembleOptions) (string, error) {
+	if len(inputs) == 0 {
+		return "", apperror.InvalidArgument("至少需要一帧图像", nil)
+	}
+	if opts.Delay <= 0 {
+		return "", apperror.InvalidArgument("帧延迟必须为正数 (毫秒)", nil)
+	}
+	if opts.Loop < 0 {
+		return "", apperror.InvalidArgument("循环次数不能为负数", nil)
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputs[0],
+		OutputArg:     outputArg,
+		DesiredFormat: opts.Format,
+		InputFormat:   "gif",
+		Conflict:      opts.Conflict,
+	})
+	if err != nil {
+		return "", err
+	}
+	if !isAnimatedFormat(outFormat) {
+		return "", apperror.UnsupportedFormat("动图仅支持输出 GIF 与 WebP", nil)
+	}
+	anim := &animation{LoopCount: opts.Loop}
+	for _, input := range inputs {
+		buf, err := os.ReadFile(input)
+		if err != nil {
+			return "", apperror.InvalidInput("文件不存在或无法读取: "+input, err)
+		}
+		if DetectImageType(buf) == bimg.UNKNOWN {
+			return "", apperror.UnsupportedFormat("无法识别输入格式: "+input, nil)
+		}
+		frame, err := decodeRaster(buf)
+		if err != nil {
+			return "", err
+		}
+		if len(anim.Frames) == 0 {
+			anim.Width = frame.Rect.Dx()
+			anim.Height = frame.Rect.Dy()
+		} else if frame.Rect.Dx() != anim.Width || frame.Rect.Dy() != anim.Height {
+			frame = fitFrame(frame, anim.Width, anim.Height)
+		}
+		anim.Frames = append(anim.Frames, frame)
+		anim.Delays = append(anim.Delays, opts.Delay)
+	}
+	data, err := encodeAnimation(anim, outFormat, opts.Quality)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func ChangeAnimationSpeed(inputPath, outputArg string, opts SpeedOptions) (string, error) {
+	if opts.Factor <= 0 {
+		return "", apperror.InvalidArgument("速度倍数必须为正数", nil)
+	}
+	return rewriteAnimation(inputPath, outputArg, opts.Conflict, func(anim *animation) {
+		for i, delay := range anim.Delays {
+			anim.Delays[i] = scaleFrameDelay(delay, opts.Factor)
+		}
+	})
+}
+
+// scaleFrameDelay 按速度倍数缩放单帧延迟。加速后低于 minFrameDelay 的延迟被抬高到该值，
+// 但不超过原延迟；延迟为 0 或倍数为 1 时保持原值。
+func scaleFrameDelay(delay int, factor float64) int {
+	if delay == 0 || factor == 1 {
+		return delay
+	}
+	scaled := int(math.Round(float64(delay) / factor))
+	if floor := minInt(minFrameDelay, delay); scaled < floor {
+		scaled = floor
+	}
+	return scaled
+}
+
+func OptimizeAnimation(inputPath, outputArg string, opts OptimizeOptions) (string, error) {
+	if opts.Colors != 0 && (opts.Colors < 2 || opts.Colors > 256) {
+		return "", apperror.InvalidArgument("调色板颜色数必须在 2-256 之间", nil)
+	}
+	if opts.Fuzz < 0 {
+		return "", apperror.InvalidArgument("容差不能为负数", nil)
+	}
+	return rewriteAnimation(inputPath, outputArg, opts.Conflict, func(anim *animation) {
+		dropDuplicateFrames(anim, opts.Fuzz)
+		anim.Colors = opts.Colors
+	})
+}
+
+func readAnimation(inputPath string) (*animation, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	return decodeAnimation(buf)
+}
+
+func rewriteAnimation(inputPath, outputArg, conflict string, edit func(*animation)) (string, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	anim, err := decodeAnimation(buf)
+	if err != nil {
+		return "", err
+	}
+	edit(anim)
+	data, err := encodeAnimation(anim, outFormat, 0)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+// dropDuplicateFrames 合并与前一帧相同（逐通道差值不超过 fuzz）的帧，并把延迟累加到保留的帧上。
+func dropDuplicateFrames(anim *animation, fuzz int) {
+	if len(anim.Frames) < 2 {
+		return
+	}
+	frames := []*image.NRGBA{anim.Frames[0]}
+	delays := []int{anim.Delays[0]}
+	for i := 1; i < len(anim.Frames); i++ {
+		last := frames[len(frames)-1]
+		if framesEqual(last, anim.Frames[i], fuzz) {
+			delays[len(delays)-1] += anim.Delays[i]
+			continue
+		}
+		frames = append(frames, anim.Frames[i])
+		delays = append(delays, anim.Delays[i])
+	}
+	anim.Frames = frames
+	anim.Delays = delays
+}
+
+func framesEqual(a, b *image.NRGBA, fuzz int) bool {
+	if a.Rect != b.Rect {
+		return false
+	}
+	if fuzz == 0 {
+		return bytes.Equal(a.Pix, b.Pix)
+	}
+	for i := range a.Pix {
+		diff := int(a.Pix[i]) - int(b.Pix[i])
+		if diff > fuzz || diff < -fuzz {
+			return false
+		}
+	}
+	return true
+}
+
+func fitFrame(frame *image.NRGBA, width, height int) *image.NRGBA {
+	transform := resizeFrameTransform(width, height, bimg.Options{Embed: true, Enlarge: true})
+	fitted, _ := transform(frame)
+	return fitted
+}
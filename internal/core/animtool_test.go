@@ -0,0 +1,24 @@
+package core
+
+import "testing"
+
+func TestScaleFrameDelay(t *testing.T) {
+	for _, tc := range []struct {
+		delay  int
+		factor float64
+		want   int
+	}{
+		{100, 1, 100},
+		{5, 1, 5},
+		{0, 4, 0},
+		{100, 2, 50},
+		{100, 0.5, 200},
+		{30, 10, 10},
+		{5, 2, 5},
+		{5, 0.5, 10},
+	} {
+		if got := scaleFrameDelay(tc.delay, tc.factor); got != tc.want {
+			t.Errorf("scaleFrameDelay(%d, %v) = %d, want %d", tc.delay, tc.factor, got, tc.want)
+		}
+	}
+}
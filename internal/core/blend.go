@@ -0,0 +1,87 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// ParseBlendMode 规范化混合模式名称，空值视为 normal。
+func ParseBlendMode(value string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	switch mode {
+	case "", "normal", "over":
+		return "normal", nil
+	case "multiply", "screen", "overlay", "difference":
+		return mode, nil
+	case "soft-light", "softlight", "soft_light":
+		return "soft-light", nil
+	default:
+		return "", apperror.InvalidArgument("混合模式仅支持 normal、multiply、screen、overlay、soft-light 或 difference", nil)
+	}
+}
+
+// blendChannel 按 W3C Compositing 规范计算单通道混合结果，cb 为底色、cs 为叠加色，取值 0-1。
+func blendChannel(mode string, cb, cs float64) float64 {
+	switch mode {
+	case "multiply":
+		return cb * cs
+	case "screen":
+		return cb + cs - cb*cs
+	case "overlay":
+		return blendChannel("hard-light", cs, cb)
+	case "hard-light":
+		if cs <= 0.5 {
+			return cb * 2 * cs
+		}
+		return blendChannel("screen", cb, 2*cs-1)
+	case "soft-light":
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		d := math.Sqrt(cb)
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case "difference":
+		return math.Abs(cb - cs)
+	default:
+		return cs
+	}
+}
+
+// blendPixel 先按混合模式修正叠加色，再以 source-over 合成，底色透明处退化为普通叠加。
+func blendPixel(dst, src color.NRGBA, mode string) color.NRGBA {
+	if mode == "normal" || dst.A == 0 {
+		return blendOver(dst, src)
+	}
+	ab := float64(dst.A) / 255
+	mix := func(b, s uint8) uint8 {
+		cb, cs := float64(b)/255, float64(s)/255
+		return clampByte(((1-ab)*cs + ab*blendChannel(mode, cb, cs)) * 255)
+	}
+	src.R, src.G, src.B = mix(dst.R, src.R), mix(dst.G, src.G), mix(dst.B, src.B)
+	return blendOver(dst, src)
+}
+
+// compositeNRGBA 将图层按不透明度与混合模式叠加到画布的 (left, top) 处，超出画布的部分被裁掉。
+func compositeNRGBA(dst, src *image.NRGBA, left, top int, opacity float64, mode string) {
+	area := src.Rect.Add(image.Pt(left, top)).Intersect(dst.Rect)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			so := src.PixOffset(x-left, y-top)
+			alpha := applyOpacity(src.Pix[so+3], opacity)
+			if alpha == 0 {
+				continue
+			}
+			do := dst.PixOffset(x, y)
+			under := color.NRGBA{dst.Pix[do], dst.Pix[do+1], dst.Pix[do+2], dst.Pix[do+3]}
+			pixel := blendPixel(under, color.NRGBA{src.Pix[so], src.Pix[so+1], src.Pix[so+2], alpha}, mode)
+			dst.Pix[do], dst.Pix[do+1], dst.Pix[do+2], dst.Pix[do+3] = pixel.R, pixel.G, pixel.B, pixel.A
+		}
+	}
+}
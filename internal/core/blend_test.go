@@ -0,0 +1,70 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// blendGoldenInputs 为 4×1 的底图与叠加层：不透明叠加、半透明叠加、半透明底色与全透明底色。
+func blendGoldenInputs() (*image.NRGBA, *image.NRGBA) {
+	base := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	overlay := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	for x, alpha := range []uint8{255, 255, 128, 0} {
+		base.SetNRGBA(x, 0, color.NRGBA{R: 200, G: 100, B: 50, A: alpha})
+	}
+	for x, alpha := range []uint8{255, 128, 255, 128} {
+		overlay.SetNRGBA(x, 0, color.NRGBA{R: 100, G: 150, B: 250, A: alpha})
+	}
+	return base, overlay
+}
+
+// 期望值按 W3C Compositing and Blending Level 1 的公式独立计算，允许 ±1 的舍入误差。
+func TestCompositeBlendModes(t *testing.T) {
+	golden := map[string][4]color.NRGBA{
+		"multiply":   {{78, 59, 49, 255}, {139, 79, 50, 255}, {89, 104, 149, 255}, {100, 150, 250, 128}},
+		"screen":     {{222, 191, 251, 255}, {211, 146, 151, 255}, {161, 171, 250, 255}, {100, 150, 250, 128}},
+		"overlay":    {{188, 118, 98, 255}, {194, 109, 74, 255}, {144, 134, 174, 255}, {100, 150, 250, 128}},
+		"soft-light": {{191, 111, 111, 255}, {195, 105, 80, 255}, {146, 130, 180, 255}, {100, 150, 250, 128}},
+		"difference": {{100, 50, 200, 255}, {150, 75, 125, 255}, {100, 100, 225, 255}, {100, 150, 250, 128}},
+	}
+	for mode, want := range golden {
+		base, overlay := blendGoldenInputs()
+		compositeNRGBA(base, overlay, 0, 0, 1, mode)
+		for x := range want {
+			got := base.NRGBAAt(x, 0)
+			if !nearNRGBA(got, want[x], 1) {
+				t.Errorf("%s pixel %d = %v, want %v", mode, x, got, want[x])
+			}
+		}
+	}
+}
+
+func TestCompositeOpacityMatchesOverlayAlpha(t *testing.T) {
+	base, overlay := blendGoldenInputs()
+	compositeNRGBA(base, overlay, 0, 0, 128.0/255, "multiply")
+	want := color.NRGBA{139, 79, 50, 255}
+	if got := base.NRGBAAt(0, 0); !nearNRGBA(got, want, 1) {
+		t.Errorf("multiply at 50%% opacity = %v, want %v", got, want)
+	}
+}
+
+func TestParseBlendMode(t *testing.T) {
+	for input, want := range map[string]string{"": "normal", "over": "normal", "Multiply": "multiply", "softlight": "soft-light", "soft_light": "soft-light"} {
+		got, err := ParseBlendMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseBlendMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseBlendMode("color-dodge"); err == nil {
+		t.Error("ParseBlendMode(color-dodge) should fail")
+	}
+}
+
+func nearNRGBA(a, b color.NRGBA, tolerance int) bool {
+	diff := func(x, y uint8) bool {
+		d := int(x) - int(y)
+		return d <= tolerance && d >= -tolerance
+	}
+	return diff(a.R, b.R) && diff(a.G, b.G) && diff(a.B, b.B) && diff(a.A, b.A)
+}
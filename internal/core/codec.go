@@ -0,0 +1,231 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"golang.org/x/image/bmp"
+)
+
+// bimg 不识别的格式使用扩展的 ImageType 值，输入时先解码为 PNG 再交给 libvips，
+// 输出时先由 libvips 生成 PNG 再编码。BMP 与 QOI 为纯 Go 实现，JXL 依赖 vips 命令行工具，
+// RAW 仅支持输入。
+const (
+	BMP bimg.ImageType = iota + 100
+	QOI
+	JXL
+	RAW
+)
+
+var extendedTypeNames = map[bimg.ImageType]string{
+	BMP: "bmp",
+	QOI: "qoi",
+	JXL: "jxl",
+	RAW: "raw",
+}
+
+var vipsForeign struct {
+	once sync.Once
+	list string
+}
+
+func isExtendedType(imageType bimg.ImageType) bool {
+	_, ok := extendedTypeNames[imageType]
+	return ok
+}
+
+// DetectImageType 在 bimg 的基础上识别 BMP、QOI 与 JXL。
+func DetectImageType(buf []byte) bimg.ImageType {
+	switch {
+	case isBMP(buf):
+		return BMP
+	case isQOI(buf):
+		return QOI
+	case isJXL(buf):
+		return JXL
+	case rawKind(buf) != "":
+		return RAW
+	}
+	return bimg.DetermineImageType(buf)
+}
+
+// isBMP 除 "BM" 签名外还校验偏移 14 处的 DIB 头长度（BITMAPCOREHEADER 及 V1/V4/V5 信息头），
+// 避免以 "BM" 开头的文本等被误判。
+func isBMP(buf []byte) bool {
+	if len(buf) < 18 || buf[0] != 'B' || buf[1] != 'M' {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(buf[14:18]) {
+	case 12, 40, 108, 124:
+		return true
+	}
+	return false
+}
+
+func isJXL(buf []byte) bool {
+	if len(buf) >= 2 && buf[0] == 0xff && buf[1] == 0x0a {
+		return true
+	}
+	return len(buf) >= 12 && bytes.Equal(buf[:12], []byte{0, 0, 0, 0x0c, 'J', 'X', 'L', ' ', 0x0d, 0x0a, 0x87, 0x0a})
+}
+
+func IsTypeSupported(imageType bimg.ImageType) bool {
+	switch imageType {
+	case BMP, QOI, RAW:
+		return true
+	case JXL:
+		return hasVipsForeign("jxlload")
+	}
+	return bimg.IsTypeSupported(imageType)
+}
+
+func IsTypeSupportedSave(imageType bimg.ImageType) bool {
+	switch imageType {
+	case BMP, QOI:
+		return bimg.IsTypeSupportedSave(bimg.PNG)
+	case JXL:
+		return hasVipsForeign("jxlsave") && bimg.IsTypeSupportedSave(bimg.PNG)
+	case RAW:
+		return false
+	}
+	return bimg.IsTypeSupportedSave(imageType)
+}
+
+// hasVipsForeign 检查 vips 命令行工具是否提供指定的加载/保存器（如 jxlload）。
+func hasVipsForeign(name string) bool {
+	vipsForeign.once.Do(func() {
+		cmdPath, ok := VipsCommand()
+		if !ok {
+			return
+		}
+		output, err := exec.Command(cmdPath, "-l", "foreign").Output()
+		if err != nil {
+			return
+		}
+		vipsForeign.list = string(output)
+	})
+	return strings.Contains(vipsForeign.list, name)
+}
+
+// NormalizeImage 识别输入格式，将 bimg 无法直接处理的格式解码为 PNG。
+// 返回可交给 bimg 的缓冲区与原始格式。
+func NormalizeImage(buf []byte) ([]byte, bimg.ImageType, error) {
+	return normalizeImage(buf, RawOptions{})
+}
+
+func normalizeImage(buf []byte, raw RawOptions) ([]byte, bimg.ImageType, error) {
+	if err := validateRawOptions(raw); err != nil {
+		return nil, bimg.UNKNOWN, err
+	}
+	imageType := DetectImageType(buf)
+	if imageType == bimg.UNKNOWN {
+		return nil, bimg.UNKNOWN, apperror.UnsupportedFormat("无法识别输入格式", nil)
+	}
+	if !isExtendedType(imageType) {
+		return buf, imageType, nil
+	}
+	var img image.Image
+	var err error
+	switch imageType {
+	case BMP:
+		img, err = bmp.Decode(bytes.NewReader(buf))
+	case QOI:
+		img, err = decodeQOI(buf)
+	case RAW:
+		pngBuf, err := developRAW(buf, raw)
+		if err != nil {
+			return nil, imageType, err
+		}
+		return pngBuf, imageType, nil
+	case JXL:
+		if !hasVipsForeign("jxlload") {
+			return nil, imageType, apperror.UnsupportedFormat("JXL 需要安装支持 jxl 的 vips 命令行工具", nil)
+		}
+		pngBuf, err := vipsConvert(buf, "jxl", "png", "")
+		if err != nil {
+			return nil, imageType, err
+		}
+		return pngBuf, imageType, nil
+	}
+	if err != nil {
+		return nil, imageType, apperror.InvalidInput("无法解析图像", err)
+	}
+	pngBuf, err := encodePNG(img)
+	if err != nil {
+		return nil, imageType, err
+	}
+	return pngBuf, imageType, nil
+}
+
+// processImage 与 bimg Process 相同，额外支持输出扩展格式。
+func processImage(buf []byte, options bimg.Options) ([]byte, error) {
+	outType := options.Type
+	if !isExtendedType(outType) {
+		return bimg.NewImage(buf).Process(options)
+	}
+	options.Type = bimg.PNG
+	pngBuf, err := bimg.NewImage(buf).Process(options)
+	if err != nil {
+		return nil, err
+	}
+	return encodeExtended(pngBuf, outType, options.Quality)
+}
+
+func encodeExtended(pngBuf []byte, outType bimg.ImageType, quality int) ([]byte, error) {
+	if outType == JXL {
+		params := ""
+		if quality > 0 {
+			params = fmt.Sprintf("[Q=%d]", quality)
+		}
+		return vipsConvert(pngBuf, "png", "jxl", params)
+	}
+	decoded, err := png.Decode(bytes.NewReader(pngBuf))
+	if err != nil {
+		return nil, apperror.InvalidInput("无法解析图像", err)
+	}
+	img := toNRGBA(decoded)
+	if outType == QOI {
+		return encodeQOI(img), nil
+	}
+	var out bytes.Buffer
+	if err := bmp.Encode(&out, img); err != nil {
+		return nil, apperror.ConfigError("BMP 编码失败", err)
+	}
+	return out.Bytes(), nil
+}
+
+func vipsConvert(buf []byte, fromExt, toExt, params string) ([]byte, error) {
+	cmdPath, ok := VipsCommand()
+	if !ok {
+		return nil, apperror.UnsupportedFormat("需要安装 vips 命令行工具", nil)
+	}
+	tempDir, err := os.MkdirTemp("", "image-cli-vips-")
+	if err != nil {
+		return nil, apperror.ConfigError("无法创建临时目录", err)
+	}
+	defer os.RemoveAll(tempDir)
+	inPath := filepath.Join(tempDir, "input."+fromExt)
+	outPath := filepath.Join(tempDir, "output."+toExt)
+	if err := os.WriteFile(inPath, buf, 0o644); err != nil {
+		return nil, apperror.ConfigError("无法写入临时文件", err)
+	}
+	output, err := exec.Command(cmdPath, "copy", inPath, outPath+params).CombinedOutput()
+	if err != nil {
+		return nil, apperror.InvalidInput("vips 转换失败: "+strings.TrimSpace(string(output)), err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, apperror.ConfigError("无法读取临时文件", err)
+	}
+	return data, nil
+}
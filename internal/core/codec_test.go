@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"golang.org/x/image/bmp"
+)
+
+func TestDetectBMPRequiresDIBHeader(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := bmp.Encode(&encoded, image.NewNRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectImageType(encoded.Bytes()); got != BMP {
+		t.Errorf("DetectImageType(bmp) = %v, want BMP", got)
+	}
+	core := make([]byte, 26)
+	copy(core, "BM")
+	core[14] = 12
+	if got := DetectImageType(core); got != BMP {
+		t.Errorf("DetectImageType(OS/2 core header) = %v, want BMP", got)
+	}
+	text := []byte("BMW owners club meeting notes\n")
+	if got := DetectImageType(text); got == BMP {
+		t.Error("text starting with BM was detected as BMP")
+	}
+}
+
+func TestExtendedFormatsRoundTrip(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 7; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 36), G: uint8(y * 50), B: uint8(x * y * 7), A: 255})
+		}
+	}
+	src.SetNRGBA(3, 2, color.NRGBA{R: 10, G: 20, B: 30, A: 128})
+	pngBuf, err := encodePNG(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		imageType bimg.ImageType
+		alpha     bool
+	}{
+		{"qoi", QOI, true},
+		{"bmp", BMP, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := src
+			input := pngBuf
+			if !tc.alpha {
+				want = cloneNRGBA(src)
+				want.SetNRGBA(3, 2, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+				if input, err = encodePNG(want); err != nil {
+					t.Fatal(err)
+				}
+			}
+			encoded, err := encodeExtended(input, tc.imageType, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectImageType(encoded); got != tc.imageType {
+				t.Fatalf("DetectImageType = %v, want %v", got, tc.imageType)
+			}
+			normalized, inputType, err := NormalizeImage(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inputType != tc.imageType {
+				t.Fatalf("NormalizeImage type = %v, want %v", inputType, tc.imageType)
+			}
+			decoded, err := png.Decode(bytes.NewReader(normalized))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := toNRGBA(decoded)
+			if got.Rect != want.Rect {
+				t.Fatalf("bounds = %v, want %v", got.Rect, want.Rect)
+			}
+			for y := 0; y < 5; y++ {
+				for x := 0; x < 7; x++ {
+					if got.NRGBAAt(x, y) != want.NRGBAAt(x, y) {
+						t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, got.NRGBAAt(x, y), want.NRGBAAt(x, y))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeQOIRejectsOversizedHeader(t *testing.T) {
+	buf := make([]byte, 22)
+	copy(buf, "qoif")
+	binary.BigEndian.PutUint32(buf[4:8], 0xFFFFFFFF)
+	binary.BigEndian.PutUint32(buf[8:12], 0xFFFFFFFF)
+	buf[12], buf[13] = 4, 0
+	copy(buf[14:], qoiPadding)
+	_, _, err := NormalizeImage(buf)
+	if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != "E001" {
+		t.Fatalf("NormalizeImage(huge qoi) error = %v, want E001", err)
+	}
+}
@@ -0,0 +1,459 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"gopkg.in/yaml.v3"
+)
+
+type ComposeOptions struct {
+	DataPath string
+	Quality  int
+	Conflict string
+}
+
+// ComposeLayout 为合成布局：固定尺寸画布与按 z 排序叠加的图层。
+type ComposeLayout struct {
+	Width      int            `yaml:"width" json:"width"`
+	Height     int            `yaml:"height" json:"height"`
+	Background string         `yaml:"background" json:"background"`
+	Layers     []ComposeLayer `yaml:"layers" json:"layers"`
+}
+
+// ComposeLayer 描述单个图层，字符串字段支持 {列名}、{index} 等模板变量。
+type ComposeLayer struct {
+	Type        string   `yaml:"type" json:"type"`
+	Src         string   `yaml:"src" json:"src"`
+	Text        string   `yaml:"text" json:"text"`
+	X           int      `yaml:"x" json:"x"`
+	Y           int      `yaml:"y" json:"y"`
+	Width       int      `yaml:"width" json:"width"`
+	Height      int      `yaml:"height" json:"height"`
+	Scale       float64  `yaml:"scale" json:"scale"`
+	Fit         string   `yaml:"fit" json:"fit"`
+	Gravity     string   `yaml:"gravity" json:"gravity"`
+	Opacity     *float64 `yaml:"opacity" json:"opacity"`
+	Blend       string   `yaml:"blend" json:"blend"`
+	Z           int      `yaml:"z" json:"z"`
+	FontSize    int      `yaml:"font_size" json:"font_size"`
+	Font        string   `yaml:"font" json:"font"`
+	FontFile    string   `yaml:"font_file" json:"font_file"`
+	Color       string   `yaml:"color" json:"color"`
+	StrokeColor string   `yaml:"stroke_color" json:"stroke_color"`
+	StrokeWidth int      `yaml:"stroke_width" json:"stroke_width"`
+	Background  string   `yaml:"background" json:"background"`
+	Radius      int      `yaml:"radius" json:"radius"`
+}
+
+type composer struct {
+	layout  ComposeLayout
+	baseDir string
+	baseBuf []byte
+	cache   map[string]*image.NRGBA
+}
+
+// Compose 按布局文件合成图像；指定 CSV 数据时每行生成一张，输出路径可使用模板变量区分。
+func Compose(layoutPath, outputArg string, opts ComposeOptions) ([]string, error) {
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return nil, apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	layout, err := LoadComposeLayout(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	rows := []map[string]string{nil}
+	if opts.DataPath != "" {
+		rows, err = readComposeRows(opts.DataPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(rows) > 1 && !HasTemplate(outputArg) {
+		isDir, err := outputIsDir(outputArg)
+		if err != nil {
+			return nil, apperror.ConfigError("无法读取输出路径", err)
+		}
+		if !isDir {
+			return nil, apperror.InvalidArgument("多行数据时输出路径需为目录或包含模板变量，如 cards/{index:3}.png", nil)
+		}
+	}
+	baseBuf, err := encodePNG(image.NewNRGBA(image.Rect(0, 0, layout.Width, layout.Height)))
+	if err != nil {
+		return nil, err
+	}
+	c := &composer{
+		layout:  layout,
+		baseDir: filepath.Dir(layoutPath),
+		baseBuf: baseBuf,
+		cache:   map[string]*image.NRGBA{},
+	}
+	stem := strings.TrimSuffix(filepath.Base(layoutPath), filepath.Ext(layoutPath))
+	digits := len(strconv.Itoa(len(rows)))
+	outputs := []string{}
+	for i, row := range rows {
+		ctx := TemplateContext{Index: i + 1, Vars: row}
+		target, err := expandComposeValue(outputArg, ctx)
+		if err != nil {
+			return outputs, err
+		}
+		inputPath := layoutPath
+		if len(rows) > 1 {
+			inputPath = fmt.Sprintf("%s-%0*d", stem, digits, i+1)
+		}
+		outPath, outFormat, err := ResolveOutput(OutputSpec{
+			InputPath:     inputPath,
+			OutputArg:     target,
+			DesiredFormat: "",
+			InputFormat:   "png",
+			Conflict:      opts.Conflict,
+			Overwrite:     false,
+		})
+		if err != nil {
+			return outputs, err
+		}
+		outType, err := ImageTypeFromFormat(outFormat)
+		if err != nil {
+			return outputs, err
+		}
+		if !IsTypeSupportedSave(outType) {
+			return outputs, apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+		}
+		canvas, err := c.render(ctx)
+		if err != nil {
+			return outputs, err
+		}
+		newImage, err := encodeRaster(canvas, outType, opts.Quality)
+		if err != nil {
+			return outputs, err
+		}
+		if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+			return outputs, apperror.ConfigError("无法写入输出文件", err)
+		}
+		outputs = append(outputs, outPath)
+	}
+	return outputs, nil
+}
+
+// LoadComposeLayout 读取 YAML 或 JSON 布局文件并校验图层参数。
+func LoadComposeLayout(path string) (ComposeLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ComposeLayout{}, apperror.InvalidInput("无法读取布局文件", err)
+	}
+	var layout ComposeLayout
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&layout)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&layout)
+	}
+	if err != nil && err != io.EOF {
+		return ComposeLayout{}, apperror.InvalidArgument("布局文件解析失败", err)
+	}
+	if layout.Width <= 0 || layout.Height <= 0 {
+		return ComposeLayout{}, apperror.InvalidArgument("布局需指定画布宽高", nil)
+	}
+	if !isTransparentColor(layout.Background) && !HasTemplate(layout.Background) {
+		if _, ok := parseColor(layout.Background); !ok {
+			return ComposeLayout{}, apperror.InvalidArgument("画布背景色无效", nil)
+		}
+	}
+	if len(layout.Layers) == 0 {
+		return ComposeLayout{}, apperror.InvalidArgument("布局至少需要一个图层", nil)
+	}
+	for i := range layout.Layers {
+		layer := &layout.Layers[i]
+		layer.Type = strings.ToLower(strings.TrimSpace(layer.Type))
+		if err := validateComposeLayer(*layer, i+1); err != nil {
+			return ComposeLayout{}, err
+		}
+		layer.Blend, _ = ParseBlendMode(layer.Blend)
+	}
+	sort.SliceStable(layout.Layers, func(a, b int) bool {
+		return layout.Layers[a].Z < layout.Layers[b].Z
+	})
+	return layout, nil
+}
+
+func validateComposeLayer(layer ComposeLayer, index int) error {
+	prefix := fmt.Sprintf("图层 %d: ", index)
+	switch layer.Type {
+	case "image":
+		if layer.Src == "" {
+			return apperror.InvalidArgument(prefix+"图片图层需指定 src", nil)
+		}
+		if layer.Scale < 0 || layer.Scale > 1 {
+			return apperror.InvalidArgument(prefix+"缩放比例必须在 0-1 之间", nil)
+		}
+		if layer.Scale > 0 && (layer.Width > 0 || layer.Height > 0) {
+			return apperror.InvalidArgument(prefix+"scale 与 width/height 不可同时使用", nil)
+		}
+		switch strings.ToLower(layer.Fit) {
+		case "", "cover", "contain", "fill":
+		default:
+			return apperror.InvalidArgument(prefix+"fit 仅支持 cover、contain 或 fill", nil)
+		}
+	case "text":
+		if layer.Text == "" {
+			return apperror.InvalidArgument(prefix+"文字图层需指定 text", nil)
+		}
+	case "rect":
+		if layer.Radius < 0 {
+			return apperror.InvalidArgument(prefix+"圆角半径不能为负数", nil)
+		}
+	default:
+		return apperror.InvalidArgument(prefix+"图层类型仅支持 image、text 或 rect", nil)
+	}
+	if layer.Width < 0 || layer.Height < 0 {
+		return apperror.InvalidArgument(prefix+"宽高不能为负数", nil)
+	}
+	if layer.Opacity != nil && (*layer.Opacity < 0 || *layer.Opacity > 1) {
+		return apperror.InvalidArgument(prefix+"不透明度必须在 0-1 之间", nil)
+	}
+	if _, _, err := gravityPosition(0, 0, 0, 0, composeGravity(layer.Gravity), 0, 0); err != nil {
+		return apperror.InvalidArgument(prefix+"gravity 参数无效", nil)
+	}
+	if _, err := ParseBlendMode(layer.Blend); err != nil {
+		return apperror.InvalidArgument(prefix+"不支持的混合模式: "+layer.Blend, nil)
+	}
+	return nil
+}
+
+// readComposeRows 读取带表头的 CSV，每行按列名映射为模板变量。
+func readComposeRows(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, apperror.InvalidInput("无法读取数据文件", err)
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, apperror.InvalidArgument("CSV 解析失败", err)
+	}
+	if len(records) < 2 {
+		return nil, apperror.InvalidArgument("CSV 需包含表头及至少一行数据", nil)
+	}
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+		if IsReservedTemplateVar(header[i]) {
+			return nil, apperror.InvalidArgument("CSV 列名与内置模板变量冲突: "+header[i], nil)
+		}
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if name == "" {
+				continue
+			}
+			if i < len(record) {
+				row[name] = record[i]
+			} else {
+				row[name] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func expandComposeValue(value string, ctx TemplateContext) (string, error) {
+	if !HasTemplate(value) {
+		return value, nil
+	}
+	return ExpandTemplate(value, ctx)
+}
+
+func composeGravity(gravity string) string {
+	if strings.TrimSpace(gravity) == "" {
+		return "northwest"
+	}
+	return gravity
+}
+
+func (c *composer) render(ctx TemplateContext) (*image.NRGBA, error) {
+	canvas := image.NewNRGBA(image.Rect(0, 0, c.layout.Width, c.layout.Height))
+	background, err := expandComposeValue(c.layout.Background, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if fill, ok := parseColor(background); ok {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(fill), image.Point{}, draw.Src)
+	}
+	for i, layer := range c.layout.Layers {
+		img, err := c.renderLayer(layer, ctx)
+		if err != nil {
+			return nil, err
+		}
+		left, top, err := gravityPosition(canvas.Rect.Dx(), canvas.Rect.Dy(), img.Rect.Dx(), img.Rect.Dy(), composeGravity(layer.Gravity), layer.X, layer.Y)
+		if err != nil {
+			return nil, apperror.InvalidArgument(fmt.Sprintf("图层 %d: gravity 参数无效", i+1), nil)
+		}
+		opacity := 1.0
+		if layer.Opacity != nil {
+			opacity = *layer.Opacity
+		}
+		compositeNRGBA(canvas, img, left, top, opacity, layer.Blend)
+	}
+	return canvas, nil
+}
+
+func (c *composer) renderLayer(layer ComposeLayer, ctx TemplateContext) (*image.NRGBA, error) {
+	switch layer.Type {
+	case "image":
+		src, err := expandComposeValue(layer.Src, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.imageLayer(c.resolvePath(src), layer)
+	case "text":
+		text, err := expandComposeValue(layer.Text, ctx)
+		if err != nil {
+			return nil, err
+		}
+		textColor, err := expandComposeValue(layer.Color, ctx)
+		if err != nil {
+			return nil, err
+		}
+		fontFile := layer.FontFile
+		if fontFile != "" {
+			fontFile = c.resolvePath(fontFile)
+		}
+		buf, err := buildWatermarkBuffer(c.baseBuf, WatermarkOptions{
+			Text:        text,
+			Opacity:     1,
+			FontSize:    layer.FontSize,
+			Font:        layer.Font,
+			FontFile:    fontFile,
+			Color:       textColor,
+			StrokeColor: layer.StrokeColor,
+			StrokeWidth: layer.StrokeWidth,
+			Background:  layer.Background,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return decodeRaster(buf)
+	default:
+		fillValue, err := expandComposeValue(layer.Color, ctx)
+		if err != nil {
+			return nil, err
+		}
+		fill := color.NRGBA{A: 255}
+		if fillValue != "" {
+			parsed, ok := parseColor(fillValue)
+			if !ok && !isTransparentColor(fillValue) {
+				return nil, apperror.InvalidArgument("矩形颜色无效: "+fillValue, nil)
+			}
+			fill = parsed
+		}
+		return rectLayer(c.layerSize(layer.Width, c.layout.Width), c.layerSize(layer.Height, c.layout.Height), layer.Radius, fill), nil
+	}
+}
+
+// imageLayer 加载并缩放图片图层，结果按参数缓存，逐行渲染时复用背景等固定图片。
+func (c *composer) imageLayer(path string, layer ComposeLayer) (*image.NRGBA, error) {
+	key := fmt.Sprintf("%s|%d|%d|%g|%s", path, layer.Width, layer.Height, layer.Scale, layer.Fit)
+	if cached, ok := c.cache[key]; ok {
+		return cached, nil
+	}
+	var img *image.NRGBA
+	if layer.Scale > 0 {
+		buf, err := buildWatermarkBuffer(c.baseBuf, WatermarkOptions{LogoPath: path, Scale: layer.Scale})
+		if err != nil {
+			return nil, err
+		}
+		img, err = decodeRaster(buf)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, apperror.InvalidInput("无法读取图层图片: "+path, err)
+		}
+		img, err = decodeRaster(buf)
+		if err != nil {
+			return nil, err
+		}
+		img = fitLayer(img, layer.Width, layer.Height, strings.ToLower(layer.Fit))
+	}
+	c.cache[key] = img
+	return img, nil
+}
+
+func (c *composer) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.baseDir, path)
+}
+
+func (c *composer) layerSize(value, canvas int) int {
+	if value <= 0 {
+		return canvas
+	}
+	return value
+}
+
+// fitLayer 按图层尺寸缩放图片：cover 裁切铺满，contain 完整放入，fill 拉伸；只给一边时等比缩放。
+func fitLayer(img *image.NRGBA, width, height int, fit string) *image.NRGBA {
+	srcW, srcH := img.Rect.Dx(), img.Rect.Dy()
+	if width <= 0 && height <= 0 {
+		return img
+	}
+	if width <= 0 {
+		return scaleNRGBA(img, maxInt(1, int(math.Round(float64(srcW)*float64(height)/float64(srcH)))), height)
+	}
+	if height <= 0 {
+		return scaleNRGBA(img, width, maxInt(1, int(math.Round(float64(srcH)*float64(width)/float64(srcW)))))
+	}
+	switch fit {
+	case "fill":
+		return scaleNRGBA(img, width, height)
+	case "contain":
+		scale := math.Min(float64(width)/float64(srcW), float64(height)/float64(srcH))
+		return scaleNRGBA(img, maxInt(1, int(math.Round(float64(srcW)*scale))), maxInt(1, int(math.Round(float64(srcH)*scale))))
+	default:
+		scale := math.Max(float64(width)/float64(srcW), float64(height)/float64(srcH))
+		scaledW := maxInt(width, int(math.Round(float64(srcW)*scale)))
+		scaledH := maxInt(height, int(math.Round(float64(srcH)*scale)))
+		scaled := scaleNRGBA(img, scaledW, scaledH)
+		left, top := (scaledW-width)/2, (scaledH-height)/2
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), scaled, image.Pt(left, top), draw.Src)
+		return dst
+	}
+}
+
+func rectLayer(width, height, radius int, fill color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	radius = minInt(radius, minInt(width, height)/2)
+	mask := roundedMask(width, height, img.Rect, float64(radius))
+	for i := range mask {
+		offset := i * 4
+		img.Pix[offset], img.Pix[offset+1], img.Pix[offset+2] = fill.R, fill.G, fill.B
+		img.Pix[offset+3] = uint8(float64(fill.A)*mask[i] + 0.5)
+	}
+	return img
+}
@@ -15,6 +15,7 @@ type CompressOptions struct {
 	Aggressive     bool
 	Conflict       string
 	DefaultQuality int
+	Raw            RawOptions
 }
 
 func Compress(inputPath, outputArg string, opts CompressOptions) (string, error) {
@@ -22,9 +23,9 @@ func Compress(inputPath, outputArg string, opts CompressOptions) (string, error)
 	if err != nil {
 		return "", apperror.InvalidInput("文件不存在或无法读取", err)
 	}
-	inputType := bimg.DetermineImageType(buf)
-	if inputType == bimg.UNKNOWN {
-		return "", apperror.UnsupportedFormat("无法识别输入格式", nil)
+	buf, inputType, err := normalizeImage(buf, opts.Raw)
+	if err != nil {
+		return "", err
 	}
 	inputFormat := FormatFromImageType(inputType)
 	if opts.Quality <= 0 {
@@ -48,7 +49,7 @@ func Compress(inputPath, outputArg string, opts CompressOptions) (string, error)
 	if err != nil {
 		return "", err
 	}
-	if !bimg.IsTypeSupportedSave(outType) {
+	if !IsTypeSupportedSave(outType) {
 		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
 	}
 	quality := opts.Quality
@@ -112,7 +113,7 @@ func processWithQuality(buf []byte, outType bimg.ImageType, quality int) ([]byte
 	if quality > 0 {
 		options.Quality = quality
 	}
-	newImage, err := bimg.NewImage(buf).Process(options)
+	newImage, err := processImage(buf, options)
 	if err != nil {
 		return nil, apperror.InvalidInput("图像处理失败", err)
 	}
@@ -17,6 +17,12 @@ type ConvertOptions struct {
 	Overwrite bool
 	Conflict  string
 	ICOSizes  []int
+	Pages     string
+	DPI       int
+	Density   int
+	Width     int
+	Height    int
+	Raw       RawOptions
 }
 
 func Convert(inputPath, outputArg string, opts ConvertOptions) (string, error) {
@@ -24,11 +30,29 @@ func Convert(inputPath, outputArg string, opts ConvertOptions) (string, error) {
 	if err != nil {
 		return "", apperror.InvalidInput("文件不存在或无法读取", err)
 	}
-	inputType := bimg.DetermineImageType(buf)
-	if inputType == bimg.UNKNOWN {
-		return "", apperror.UnsupportedFormat("无法识别输入格式", nil)
+	buf, inputType, err := normalizeImage(buf, opts.Raw)
+	if err != nil {
+		return "", err
 	}
 	inputFormat := FormatFromImageType(inputType)
+	if opts.DPI < 0 || opts.Density < 0 || opts.Width < 0 || opts.Height < 0 {
+		return "", apperror.InvalidArgument("DPI 与宽高不能为负数", nil)
+	}
+	if opts.DPI > 0 && inputFormat != "pdf" {
+		return "", apperror.InvalidArgument("--dpi 仅适用于 PDF 输入", nil)
+	}
+	if opts.Density > 0 || opts.Width > 0 || opts.Height > 0 {
+		if inputType != bimg.SVG {
+			return "", apperror.InvalidArgument("--density/--width/--height 仅适用于 SVG 输入", nil)
+		}
+		if opts.Density > 0 && (opts.Width > 0 || opts.Height > 0) {
+			return "", apperror.InvalidArgument("--density 不能与 --width/--height 同时使用", nil)
+		}
+		buf, err = renderSVGAt(buf, opts.Density, opts.Width, opts.Height)
+		if err != nil {
+			return "", err
+		}
+	}
 	outPath, outFormat, err := ResolveOutput(OutputSpec{
 		InputPath:     inputPath,
 		OutputArg:     outputArg,
@@ -43,20 +67,40 @@ func Convert(inputPath, outputArg string, opts ConvertOptions) (string, error) {
 	if outFormat == "ico" {
 		return convertToICO(buf, outPath, opts.ICOSizes)
 	}
+	if outFormat == "icns" {
+		return convertToICNS(buf, outPath)
+	}
 	outType, err := ImageTypeFromFormat(outFormat)
 	if err != nil {
 		return "", err
 	}
-	if !bimg.IsTypeSupportedSave(outType) {
+	if !IsTypeSupportedSave(outType) {
 		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
 	}
 	options := bimg.Options{Type: outType}
 	if opts.Quality > 0 {
 		options.Quality = opts.Quality
 	}
-	newImage, err := bimg.NewImage(buf).Process(options)
-	if err != nil {
-		return "", apperror.InvalidInput("图像处理失败", err)
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, nil)
+		if err != nil {
+			return "", err
+		}
+	} else if opts.DPI > 0 {
+		pageBuf, err := rasterizePage(inputPath, inputFormat, 0, opts.DPI)
+		if err != nil {
+			return "", err
+		}
+		newImage, err = processWithQuality(pageBuf, outType, opts.Quality)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		newImage, err = processImage(buf, options)
+		if err != nil {
+			return "", apperror.InvalidInput("图像处理失败", err)
+		}
 	}
 	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
 		return "", apperror.ConfigError("无法写入输出文件", err)
@@ -82,7 +126,7 @@ func convertToICO(buf []byte, outPath string, sizes []int) (string, error) {
 	defer os.RemoveAll(tempDir)
 	inputPath := filepath.Join(tempDir, "input.png")
 	options := bimg.Options{Type: bimg.PNG}
-	newImage, err := bimg.NewImage(buf).Process(options)
+	newImage, err := processImage(buf, options)
 	if err != nil {
 		return "", apperror.InvalidInput("图像处理失败", err)
 	}
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+func TestConvertRejectsDPIForNonPDF(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 16, 16)
+	_, err := Convert(input, filepath.Join(dir, "out.jpg"), ConvertOptions{DPI: 300})
+	if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != "E007" {
+		t.Fatalf("Convert(png, dpi=300) error = %v, want E007", err)
+	}
+}
@@ -0,0 +1,302 @@
+package core
+
+import (
+	"image"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// Upscaler 调用外部超分辨率服务放大 PNG 图像，返回放大后的图像。
+type Upscaler func(pngData []byte, scale int) ([]byte, error)
+
+// EnhanceOptions 为图像增强参数。Upscaler 为空时在本地处理：降噪、Lanczos3 放大、USM 锐化；
+// 否则由服务放大，降噪与锐化交由服务处理。
+type EnhanceOptions struct {
+	Scale         int
+	Denoise       bool
+	DenoiseMethod string
+	Sharpen       bool
+	Format        string
+	Quality       int
+	Conflict      string
+	Upscaler      Upscaler
+}
+
+// Enhance 放大并增强图像，输出尺寸为原图的 Scale 倍。
+func Enhance(inputPath, outputArg string, opts EnhanceOptions) (string, error) {
+	if opts.Scale < 1 || opts.Scale > 8 {
+		return "", apperror.InvalidArgument("放大倍数必须在 1-8 之间", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	denoiseMethod := strings.ToLower(strings.TrimSpace(opts.DenoiseMethod))
+	if denoiseMethod == "" {
+		denoiseMethod = "bilateral"
+	}
+	if denoiseMethod != "bilateral" && denoiseMethod != "median" {
+		return "", apperror.InvalidArgument("降噪方式仅支持 bilateral 或 median", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: opts.Format,
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	width, height := img.Rect.Dx()*opts.Scale, img.Rect.Dy()*opts.Scale
+	if opts.Upscaler != nil {
+		// 服务接收解码后（已按 EXIF 方向校正、统一为 PNG）的图像，与本地处理的输入一致。
+		pngBuf, err := encodePNG(img)
+		if err != nil {
+			return "", err
+		}
+		result, err := opts.Upscaler(pngBuf, opts.Scale)
+		if err != nil {
+			return "", err
+		}
+		img, err = decodeRaster(result)
+		if err != nil {
+			return "", apperror.New("E603", "无法解析放大结果", err.Error(), err)
+		}
+		if img.Rect.Dx() != width || img.Rect.Dy() != height {
+			img = lanczosResize(img, width, height)
+		}
+	} else {
+		if opts.Denoise {
+			if denoiseMethod == "median" {
+				img = medianFilter(img, 1)
+			} else {
+				img = bilateralFilter(img, 2, 2, 25)
+			}
+		}
+		if opts.Scale > 1 {
+			img = lanczosResize(img, width, height)
+		}
+		if opts.Sharpen {
+			unsharpMask(img, 1, 0.8, 2)
+		}
+	}
+	newImage, err := encodeRaster(img, outType, opts.Quality)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+type lanczosTap struct {
+	start   int
+	weights []float64
+}
+
+func lanczosKernel(x float64) float64 {
+	const a = 3
+	if x == 0 {
+		return 1
+	}
+	if x <= -a || x >= a {
+		return 0
+	}
+	px := math.Pi * x
+	return a * math.Sin(px) * math.Sin(px/a) / (px * px)
+}
+
+// lanczosTaps 计算一维 Lanczos3 重采样权重，缩小时按比例拉宽核以避免混叠。
+func lanczosTaps(srcLen, dstLen int) []lanczosTap {
+	ratio := float64(srcLen) / float64(dstLen)
+	stretch := math.Max(ratio, 1)
+	support := 3 * stretch
+	taps := make([]lanczosTap, dstLen)
+	for i := range taps {
+		center := (float64(i)+0.5)*ratio - 0.5
+		start := int(math.Ceil(center - support))
+		end := int(math.Floor(center + support))
+		weights := make([]float64, 0, end-start+1)
+		sum := 0.0
+		for j := start; j <= end; j++ {
+			w := lanczosKernel((float64(j) - center) / stretch)
+			weights = append(weights, w)
+			sum += w
+		}
+		if sum != 0 {
+			for k := range weights {
+				weights[k] /= sum
+			}
+		}
+		taps[i] = lanczosTap{start: start, weights: weights}
+	}
+	return taps
+}
+
+// lanczosResize 以 Lanczos3 重采样缩放图像，在预乘透明度空间中计算以避免透明边缘发黑。
+func lanczosResize(src *image.NRGBA, width, height int) *image.NRGBA {
+	srcW, srcH := src.Rect.Dx(), src.Rect.Dy()
+	premul := make([]float64, srcW*srcH*4)
+	for y := 0; y < srcH; y++ {
+		for x := 0; x < srcW; x++ {
+			offset := src.PixOffset(x, y)
+			alpha := float64(src.Pix[offset+3]) / 255
+			i := (y*srcW + x) * 4
+			premul[i] = float64(src.Pix[offset]) * alpha
+			premul[i+1] = float64(src.Pix[offset+1]) * alpha
+			premul[i+2] = float64(src.Pix[offset+2]) * alpha
+			premul[i+3] = float64(src.Pix[offset+3])
+		}
+	}
+	clampIndex := func(v, n int) int {
+		return minInt(maxInt(v, 0), n-1)
+	}
+	horizontal := make([]float64, width*srcH*4)
+	for x, tap := range lanczosTaps(srcW, width) {
+		for y := 0; y < srcH; y++ {
+			var acc [4]float64
+			for k, w := range tap.weights {
+				i := (y*srcW + clampIndex(tap.start+k, srcW)) * 4
+				for c := 0; c < 4; c++ {
+					acc[c] += premul[i+c] * w
+				}
+			}
+			copy(horizontal[(y*width+x)*4:], acc[:])
+		}
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y, tap := range lanczosTaps(srcH, height) {
+		for x := 0; x < width; x++ {
+			var acc [4]float64
+			for k, w := range tap.weights {
+				i := (clampIndex(tap.start+k, srcH)*width + x) * 4
+				for c := 0; c < 4; c++ {
+					acc[c] += horizontal[i+c] * w
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			alpha := clampByte(acc[3])
+			dst.Pix[offset+3] = alpha
+			if alpha == 0 {
+				continue
+			}
+			scale := 255 / acc[3]
+			dst.Pix[offset] = clampByte(acc[0] * scale)
+			dst.Pix[offset+1] = clampByte(acc[1] * scale)
+			dst.Pix[offset+2] = clampByte(acc[2] * scale)
+		}
+	}
+	return dst
+}
+
+// medianFilter 对 RGB 通道做 (2r+1)×(2r+1) 中值滤波，适合去除椒盐噪点。
+func medianFilter(src *image.NRGBA, radius int) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := cloneNRGBA(src)
+	window := make([]int, 0, (2*radius+1)*(2*radius+1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				window = window[:0]
+				for dy := -radius; dy <= radius; dy++ {
+					for dx := -radius; dx <= radius; dx++ {
+						sx := minInt(maxInt(x+dx, 0), w-1)
+						sy := minInt(maxInt(y+dy, 0), h-1)
+						window = append(window, int(src.Pix[src.PixOffset(sx, sy)+c]))
+					}
+				}
+				sort.Ints(window)
+				dst.Pix[offset+c] = uint8(window[len(window)/2])
+			}
+		}
+	}
+	return dst
+}
+
+// bilateralFilter 双边滤波：按空间距离与颜色差异加权平均，降噪的同时保留边缘。
+func bilateralFilter(src *image.NRGBA, radius int, sigmaSpace, sigmaColor float64) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := cloneNRGBA(src)
+	size := 2*radius + 1
+	spatial := make([]float64, size*size)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			spatial[(dy+radius)*size+dx+radius] = math.Exp(-float64(dx*dx+dy*dy) / (2 * sigmaSpace * sigmaSpace))
+		}
+	}
+	rangeWeight := make([]float64, 3*255*255+1)
+	for d := range rangeWeight {
+		rangeWeight[d] = math.Exp(-float64(d) / (2 * sigmaColor * sigmaColor))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			center := src.Pix[src.PixOffset(x, y) : src.PixOffset(x, y)+3]
+			var acc [3]float64
+			total := 0.0
+			for dy := -radius; dy <= radius; dy++ {
+				sy := minInt(maxInt(y+dy, 0), h-1)
+				for dx := -radius; dx <= radius; dx++ {
+					sx := minInt(maxInt(x+dx, 0), w-1)
+					offset := src.PixOffset(sx, sy)
+					dr := int(src.Pix[offset]) - int(center[0])
+					dg := int(src.Pix[offset+1]) - int(center[1])
+					db := int(src.Pix[offset+2]) - int(center[2])
+					weight := spatial[(dy+radius)*size+dx+radius] * rangeWeight[dr*dr+dg*dg+db*db]
+					acc[0] += float64(src.Pix[offset]) * weight
+					acc[1] += float64(src.Pix[offset+1]) * weight
+					acc[2] += float64(src.Pix[offset+2]) * weight
+					total += weight
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				dst.Pix[offset+c] = clampByte(acc[c] / total)
+			}
+		}
+	}
+	return dst
+}
+
+// unsharpMask 以模糊副本为基准增强细节，差值不超过 threshold 的平坦区域保持不变以免放大噪点。
+func unsharpMask(img *image.NRGBA, radius int, amount float64, threshold int) {
+	blurred := cloneNRGBA(img)
+	boxBlurRegion(blurred, blurred.Rect, radius)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		base := blurred.Pix[y*blurred.Stride : y*blurred.Stride+blurred.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			for c := 0; c < 3; c++ {
+				diff := int(row[i+c]) - int(base[i+c])
+				if diff > threshold || diff < -threshold {
+					row[i+c] = clampByte(float64(row[i+c]) + amount*float64(diff))
+				}
+			}
+		}
+	}
+}
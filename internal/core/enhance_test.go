@@ -0,0 +1,53 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnhanceUpscalerReceivesDecodedPNG(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 40, 30)
+	var sent []byte
+	outPath, err := Enhance(input, filepath.Join(dir, "out.png"), EnhanceOptions{
+		Scale: 3,
+		Upscaler: func(pngData []byte, scale int) ([]byte, error) {
+			sent = pngData
+			// 模拟固定 4 倍放大的服务，结果应被缩放到 3 倍。
+			return encodePNG(image.NewNRGBA(image.Rect(0, 0, 160, 120)))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(sent))
+	if err != nil || format != "png" || cfg.Width != 40 || cfg.Height != 30 {
+		t.Fatalf("upscaler received %s %dx%d (%v), want 40x30 png", format, cfg.Width, cfg.Height, err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || out.Width != 120 || out.Height != 90 {
+		t.Fatalf("output = %dx%d (%v), want 120x90", out.Width, out.Height, err)
+	}
+}
+
+func TestLanczosResizeKeepsFlatColor(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+	for i := 0; i < len(src.Pix); i += 4 {
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = 10, 120, 240, 255
+	}
+	for _, size := range []image.Point{{21, 15}, {3, 2}} {
+		dst := lanczosResize(src, size.X, size.Y)
+		for i := 0; i < len(dst.Pix); i += 4 {
+			if dst.Pix[i] != 10 || dst.Pix[i+1] != 120 || dst.Pix[i+2] != 240 || dst.Pix[i+3] != 255 {
+				t.Fatalf("%v: pixel %d = %v, want flat color", size, i/4, dst.Pix[i:i+4])
+			}
+		}
+	}
+}
@@ -26,3 +26,17 @@ func ImageMagickIdentifyCommand() (string, []string, bool) {
 	}
 	return "", nil, false
 }
+
+func VipsCommand() (string, bool) {
+	if path, err := exec.LookPath("vips"); err == nil {
+		return path, true
+	}
+	return "", false
+}
+
+func VipsHeaderCommand() (string, bool) {
+	if path, err := exec.LookPath("vipsheader"); err == nil {
+		return path, true
+	}
+	return "", false
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// FilterOptions 中各滤镜可组合使用，按模糊、锐化、灰度、怀旧、反色、阈值、像素化的顺序执行。
+// 模糊、锐化与灰度由 libvips 处理，其余在 Go 中逐像素处理。
+type FilterOptions struct {
+	Blur          float64
+	Sharpen       float64
+	SharpenFlat   float64
+	SharpenJagged float64
+	Grayscale     bool
+	Sepia         bool
+	Negate        bool
+	Threshold     int
+	Pixelate      int
+	Quality       int
+	Conflict      string
+}
+
+// Filter 对图像应用滤镜，动图逐帧处理。
+func Filter(inputPath, outputArg string, opts FilterOptions) (string, error) {
+	if err := validateFilterOptions(opts); err != nil {
+		return "", err
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, FilterTransform(opts))
+	} else if !hasPixelFilters(opts) {
+		options := filterOptions(opts)
+		options.Type = outType
+		options.Quality = opts.Quality
+		newImage, err = processImage(buf, options)
+		if err != nil {
+			err = apperror.InvalidInput("图像处理失败", err)
+		}
+	} else {
+		newImage, err = filterStatic(buf, outType, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func validateFilterOptions(opts FilterOptions) error {
+	if opts.Blur < 0 || opts.Sharpen < 0 || opts.SharpenFlat < 0 || opts.SharpenJagged < 0 {
+		return apperror.InvalidArgument("模糊与锐化参数不能为负数", nil)
+	}
+	if opts.Threshold < 0 || opts.Threshold > 255 {
+		return apperror.InvalidArgument("阈值必须在 0-255 之间", nil)
+	}
+	if opts.Pixelate < 0 || opts.Pixelate == 1 {
+		return apperror.InvalidArgument("像素块尺寸必须大于 1", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	if !hasPixelFilters(opts) && !hasVipsFilters(opts) {
+		return apperror.InvalidArgument("至少需要指定一种滤镜", nil)
+	}
+	return nil
+}
+
+func hasVipsFilters(opts FilterOptions) bool {
+	return opts.Blur > 0 || opts.Sharpen > 0 || opts.Grayscale
+}
+
+func hasPixelFilters(opts FilterOptions) bool {
+	return opts.Sepia || opts.Negate || opts.Threshold > 0 || opts.Pixelate > 1
+}
+
+// filterOptions 将模糊、锐化与灰度转换为 bimg 参数。libvips 以 1+radius/2 推算锐化 sigma。
+func filterOptions(opts FilterOptions) bimg.Options {
+	var options bimg.Options
+	if opts.Blur > 0 {
+		options.GaussianBlur = bimg.GaussianBlur{Sigma: opts.Blur, MinAmpl: 0.2}
+	}
+	if opts.Sharpen > 0 {
+		flat, jagged := opts.SharpenFlat, opts.SharpenJagged
+		if flat == 0 {
+			flat = 1
+		}
+		if jagged == 0 {
+			jagged = 2
+		}
+		options.Sharpen = bimg.Sharpen{
+			Radius: maxInt(1, int(math.Round((opts.Sharpen-1)*2))),
+			X1:     2,
+			Y2:     10,
+			Y3:     20,
+			M1:     flat,
+			M2:     jagged,
+		}
+	}
+	if opts.Grayscale {
+		options.Interpretation = bimg.InterpretationBW
+	}
+	return options
+}
+
+func filterStatic(buf []byte, outType bimg.ImageType, opts FilterOptions) ([]byte, error) {
+	if hasVipsFilters(opts) {
+		options := filterOptions(opts)
+		options.Type = bimg.PNG
+		processed, err := processImage(buf, options)
+		if err != nil {
+			return nil, apperror.InvalidInput("图像处理失败", err)
+		}
+		buf = processed
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return nil, err
+	}
+	applyPixelFilters(img, opts)
+	return encodeRaster(img, outType, opts.Quality)
+}
+
+// FilterTransform 返回逐帧滤镜函数，调用前需先校验参数。
+func FilterTransform(opts FilterOptions) frameTransform {
+	options := filterOptions(opts)
+	return func(frame *image.NRGBA) (*image.NRGBA, error) {
+		var dst *image.NRGBA
+		if hasVipsFilters(opts) {
+			pngBuf, err := encodePNG(frame)
+			if err != nil {
+				return nil, err
+			}
+			options.Type = bimg.PNG
+			processed, err := bimg.NewImage(pngBuf).Process(options)
+			if err != nil {
+				return nil, apperror.InvalidInput("图像处理失败", err)
+			}
+			decoded, err := png.Decode(bytes.NewReader(processed))
+			if err != nil {
+				return nil, apperror.InvalidInput("无法解析图像", err)
+			}
+			dst = toNRGBA(decoded)
+		} else {
+			dst = cloneNRGBA(frame)
+		}
+		applyPixelFilters(dst, opts)
+		return dst, nil
+	}
+}
+
+func applyPixelFilters(img *image.NRGBA, opts FilterOptions) {
+	if opts.Sepia {
+		applyColorMatrix(img, [9]float64{
+			0.393, 0.769, 0.189,
+			0.349, 0.686, 0.168,
+			0.272, 0.534, 0.131,
+		})
+	}
+	if opts.Negate {
+		var lut [256]uint8
+		for v := range lut {
+			lut[v] = uint8(255 - v)
+		}
+		applyChannelLUT(img, [3][256]uint8{lut, lut, lut})
+	}
+	if opts.Threshold > 0 {
+		thresholdNRGBA(img, uint8(opts.Threshold))
+	}
+	if opts.Pixelate > 1 {
+		pixelateNRGBA(img, img.Rect, opts.Pixelate)
+	}
+}
+
+func thresholdNRGBA(img *image.NRGBA, level uint8) {
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			luma := 0.299*float64(row[i]) + 0.587*float64(row[i+1]) + 0.114*float64(row[i+2])
+			value := uint8(0)
+			if luma >= float64(level) {
+				value = 255
+			}
+			row[i], row[i+1], row[i+2] = value, value, value
+		}
+	}
+}
+
+// pixelateNRGBA 将区域内每个 block×block 方块替换为其平均色（按透明度加权）。
+func pixelateNRGBA(img *image.NRGBA, area image.Rectangle, block int) {
+	area = area.Intersect(img.Rect)
+	for by := area.Min.Y; by < area.Max.Y; by += block {
+		for bx := area.Min.X; bx < area.Max.X; bx += block {
+			cell := image.Rect(bx, by, bx+block, by+block).Intersect(area)
+			var r, g, b, a, n int
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					offset := img.PixOffset(x, y)
+					alpha := int(img.Pix[offset+3])
+					r += int(img.Pix[offset]) * alpha
+					g += int(img.Pix[offset+1]) * alpha
+					b += int(img.Pix[offset+2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			var avg [4]uint8
+			if a > 0 {
+				avg = [4]uint8{uint8(r / a), uint8(g / a), uint8(b / a), uint8(a / n)}
+			}
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					offset := img.PixOffset(x, y)
+					copy(img.Pix[offset:offset+4], avg[:])
+				}
+			}
+		}
+	}
+}
@@ -16,6 +16,12 @@ func NormalizeFormat(format string) string {
 	if format == "tif" {
 		return "tiff"
 	}
+	if format == "jpegxl" || format == "jpeg-xl" {
+		return "jxl"
+	}
+	if format == "dib" {
+		return "bmp"
+	}
 	return format
 }
 
@@ -40,12 +46,21 @@ func ImageTypeFromFormat(format string) (bimg.ImageType, error) {
 		return bimg.AVIF, nil
 	case "svg":
 		return bimg.SVG, nil
+	case "bmp":
+		return BMP, nil
+	case "qoi":
+		return QOI, nil
+	case "jxl":
+		return JXL, nil
 	default:
 		return bimg.UNKNOWN, apperror.UnsupportedFormat("未知输出格式", nil)
 	}
 }
 
 func FormatFromImageType(imageType bimg.ImageType) string {
+	if name, ok := extendedTypeNames[imageType]; ok {
+		return name
+	}
 	name := bimg.ImageTypeName(imageType)
 	if name == "jpeg" {
 		return "jpg"
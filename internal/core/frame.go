@@ -0,0 +1,293 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+type FrameOptions struct {
+	Pad      int
+	PadColor string
+	Radius   int
+	Shadow   string
+	Border   string
+	Flatten  string
+	Quality  int
+	Conflict string
+}
+
+type frameShadow struct {
+	OffsetX int
+	OffsetY int
+	Blur    int
+	Color   color.NRGBA
+}
+
+type frameBorder struct {
+	Width int
+	Color color.NRGBA
+}
+
+// ParseFrameShadow 解析 "x,y,blur,color" 形式的阴影参数，颜色可省略。
+func ParseFrameShadow(value string) (frameShadow, error) {
+	parts := strings.SplitN(value, ",", 4)
+	if len(parts) < 3 {
+		return frameShadow{}, apperror.InvalidArgument("阴影格式应为 x,y,blur,color", nil)
+	}
+	var nums [3]int
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			return frameShadow{}, apperror.InvalidArgument("阴影格式应为 x,y,blur,color", err)
+		}
+		nums[i] = n
+	}
+	if nums[2] < 0 {
+		return frameShadow{}, apperror.InvalidArgument("阴影模糊半径不能为负数", nil)
+	}
+	shadow := frameShadow{OffsetX: nums[0], OffsetY: nums[1], Blur: nums[2], Color: color.NRGBA{A: 96}}
+	if len(parts) == 4 {
+		parsed, ok := parseColor(parts[3])
+		if !ok {
+			return frameShadow{}, apperror.InvalidArgument("阴影颜色无效", nil)
+		}
+		shadow.Color = parsed
+	}
+	return shadow, nil
+}
+
+// ParseFrameBorder 解析 "width,color" 形式的边框参数。
+func ParseFrameBorder(value string) (frameBorder, error) {
+	parts := strings.SplitN(value, ",", 2)
+	width, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || width < 0 {
+		return frameBorder{}, apperror.InvalidArgument("边框格式应为 width,color", err)
+	}
+	border := frameBorder{Width: width, Color: color.NRGBA{A: 255}}
+	if len(parts) == 2 {
+		parsed, ok := parseColor(parts[1])
+		if !ok {
+			return frameBorder{}, apperror.InvalidArgument("边框颜色无效", nil)
+		}
+		border.Color = parsed
+	}
+	return border, nil
+}
+
+// Frame 为图像添加内边距、边框、圆角与投影。遮罩在 Go 中计算，最终由 bimg 合成。
+func Frame(inputPath, outputArg string, opts FrameOptions) (string, error) {
+	if opts.Pad < 0 || opts.Radius < 0 {
+		return "", apperror.InvalidArgument("内边距与圆角半径不能为负数", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	padColor, padOK := parseColor(opts.PadColor)
+	if !padOK && !isTransparentColor(opts.PadColor) {
+		return "", apperror.InvalidArgument("内边距颜色无效", nil)
+	}
+	var shadow *frameShadow
+	if opts.Shadow != "" {
+		parsed, err := ParseFrameShadow(opts.Shadow)
+		if err != nil {
+			return "", err
+		}
+		shadow = &parsed
+	}
+	var border frameBorder
+	if opts.Border != "" {
+		parsed, err := ParseFrameBorder(opts.Border)
+		if err != nil {
+			return "", err
+		}
+		border = parsed
+	}
+	var flatten *color.NRGBA
+	if opts.Flatten != "" {
+		parsed, ok := parseColor(opts.Flatten)
+		if !ok {
+			return "", apperror.InvalidArgument("拍平颜色无效", nil)
+		}
+		parsed.A = 255
+		flatten = &parsed
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	src, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	framed, mask := buildFrame(src, opts.Pad, padColor, padOK, border, opts.Radius)
+	if flatten == nil && outType == bimg.JPEG && (shadow != nil || !framed.Opaque()) {
+		return "", apperror.InvalidArgument("JPEG 不支持透明度，请输出 PNG/WebP 或使用 --flatten 指定背景色", nil)
+	}
+	base, left, top := frameCanvas(framed.Rect, mask, shadow, flatten)
+	baseBuf, err := encodePNG(base)
+	if err != nil {
+		return "", err
+	}
+	framedBuf, err := encodePNG(framed)
+	if err != nil {
+		return "", err
+	}
+	options := bimg.Options{
+		Type: outType,
+		WatermarkImage: bimg.WatermarkImage{
+			Left:    left,
+			Top:     top,
+			Buf:     framedBuf,
+			Opacity: 1,
+		},
+	}
+	if opts.Quality > 0 {
+		options.Quality = opts.Quality
+	}
+	newImage, err := processImage(baseBuf, options)
+	if err != nil {
+		return "", apperror.InvalidInput("图像处理失败", err)
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func isTransparentColor(value string) bool {
+	value = strings.TrimSpace(strings.ToLower(value))
+	return value == "" || value == "none" || value == "transparent"
+}
+
+// buildFrame 绘制内边距与边框并应用圆角遮罩，返回结果与外轮廓覆盖率（0-1）。
+func buildFrame(src *image.NRGBA, pad int, padColor color.NRGBA, padOK bool, border frameBorder, radius int) (*image.NRGBA, []float64) {
+	b := border.Width
+	w := src.Rect.Dx() + 2*pad + 2*b
+	h := src.Rect.Dy() + 2*pad + 2*b
+	radius = minInt(radius, minInt(w, h)/2)
+	content := image.NewNRGBA(image.Rect(0, 0, w, h))
+	inner := image.Rect(b, b, w-b, h-b)
+	if padOK {
+		draw.Draw(content, inner, image.NewUniform(padColor), image.Point{}, draw.Src)
+	}
+	draw.Draw(content, src.Rect.Add(image.Pt(b+pad, b+pad)), src, image.Point{}, draw.Over)
+
+	outerMask := roundedMask(w, h, image.Rect(0, 0, w, h), float64(radius))
+	innerMask := outerMask
+	if b > 0 {
+		innerMask = roundedMask(w, h, inner, math.Max(float64(radius-b), 0))
+	}
+	dst := image.NewNRGBA(content.Rect)
+	for i := 0; i < w*h; i++ {
+		offset := i * 4
+		var pixel color.NRGBA
+		if b > 0 && outerMask[i] > 0 {
+			pixel = border.Color
+			pixel.A = uint8(float64(border.Color.A)*outerMask[i] + 0.5)
+		}
+		top := color.NRGBA{content.Pix[offset], content.Pix[offset+1], content.Pix[offset+2], uint8(float64(content.Pix[offset+3])*innerMask[i] + 0.5)}
+		pixel = blendOver(pixel, top)
+		dst.Pix[offset], dst.Pix[offset+1], dst.Pix[offset+2], dst.Pix[offset+3] = pixel.R, pixel.G, pixel.B, pixel.A
+	}
+	return dst, outerMask
+}
+
+// roundedMask 以有向距离计算圆角矩形的抗锯齿覆盖率。
+func roundedMask(w, h int, rect image.Rectangle, radius float64) []float64 {
+	mask := make([]float64, w*h)
+	cx := float64(rect.Min.X+rect.Max.X) / 2
+	cy := float64(rect.Min.Y+rect.Max.Y) / 2
+	halfW := float64(rect.Dx())/2 - radius
+	halfH := float64(rect.Dy())/2 - radius
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			qx := math.Abs(float64(x)+0.5-cx) - halfW
+			qy := math.Abs(float64(y)+0.5-cy) - halfH
+			dist := math.Hypot(math.Max(qx, 0), math.Max(qy, 0)) + math.Min(math.Max(qx, qy), 0) - radius
+			mask[y*w+x] = math.Min(math.Max(0.5-dist, 0), 1)
+		}
+	}
+	return mask
+}
+
+func blendOver(dst, src color.NRGBA) color.NRGBA {
+	sa := float64(src.A) / 255
+	da := float64(dst.A) / 255
+	outA := sa + da*(1-sa)
+	if outA == 0 {
+		return color.NRGBA{}
+	}
+	mix := func(s, d uint8) uint8 {
+		return clampByte((float64(s)*sa + float64(d)*da*(1-sa)) / outA)
+	}
+	return color.NRGBA{mix(src.R, dst.R), mix(src.G, dst.G), mix(src.B, dst.B), clampByte(outA * 255)}
+}
+
+// frameCanvas 生成底图（透明或拍平色）并绘制模糊投影，返回内容在底图上的位置。
+func frameCanvas(rect image.Rectangle, mask []float64, shadow *frameShadow, flatten *color.NRGBA) (*image.NRGBA, int, int) {
+	bounds := rect
+	if shadow != nil {
+		bounds = bounds.Union(rect.Add(image.Pt(shadow.OffsetX, shadow.OffsetY)).Inset(-shadow.Blur))
+	}
+	left, top := -bounds.Min.X, -bounds.Min.Y
+	canvas := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	if flatten != nil {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(*flatten), image.Point{}, draw.Src)
+	}
+	if shadow == nil {
+		return canvas, left, top
+	}
+	layer := image.NewNRGBA(canvas.Rect)
+	w := rect.Dx()
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < w; x++ {
+			px, py := x+left+shadow.OffsetX, y+top+shadow.OffsetY
+			offset := layer.PixOffset(px, py)
+			layer.Pix[offset] = shadow.Color.R
+			layer.Pix[offset+1] = shadow.Color.G
+			layer.Pix[offset+2] = shadow.Color.B
+			layer.Pix[offset+3] = uint8(float64(shadow.Color.A)*mask[y*w+x] + 0.5)
+		}
+	}
+	if shadow.Blur > 0 {
+		// 透明区域沿用阴影颜色，避免模糊时颜色被黑色稀释。
+		for i := 0; i < len(layer.Pix); i += 4 {
+			if layer.Pix[i+3] == 0 {
+				layer.Pix[i], layer.Pix[i+1], layer.Pix[i+2] = shadow.Color.R, shadow.Color.G, shadow.Color.B
+			}
+		}
+		boxBlurRegion(layer, layer.Rect, maxInt(1, shadow.Blur/3))
+	}
+	draw.Draw(canvas, canvas.Bounds(), layer, image.Point{}, draw.Over)
+	return canvas, left, top
+}
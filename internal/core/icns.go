@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// icnsTypes 为以 PNG 存储的标准 ICNS 条目，按像素尺寸排列（含 @2x 变体）。
+var icnsTypes = []struct {
+	Type string
+	Size int
+}{
+	{"icp4", 16},
+	{"ic11", 32},
+	{"icp5", 32},
+	{"ic12", 64},
+	{"ic07", 128},
+	{"ic13", 256},
+	{"ic08", 256},
+	{"ic14", 512},
+	{"ic09", 512},
+	{"ic10", 1024},
+}
+
+type ICNSEntry struct {
+	Type   string
+	Width  int
+	Height int
+}
+
+func isICNS(buf []byte) bool {
+	return len(buf) >= 8 && string(buf[:4]) == "icns"
+}
+
+func convertToICNS(buf []byte, outPath string) (string, error) {
+	source, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	side := maxInt(source.Rect.Dx(), source.Rect.Dy())
+	square, _ := resizeFrameTransform(side, side, bimg.Options{Embed: true})(source)
+	data, err := encodeICNS(square)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func encodeICNS(square *image.NRGBA) ([]byte, error) {
+	var body bytes.Buffer
+	rendered := map[int][]byte{}
+	for _, item := range icnsTypes {
+		data, ok := rendered[item.Size]
+		if !ok {
+			encoded, err := encodePNG(scaleNRGBA(square, item.Size, item.Size))
+			if err != nil {
+				return nil, err
+			}
+			data = encoded
+			rendered[item.Size] = data
+		}
+		body.WriteString(item.Type)
+		binary.Write(&body, binary.BigEndian, uint32(len(data)+8))
+		body.Write(data)
+	}
+	var out bytes.Buffer
+	out.WriteString("icns")
+	binary.Write(&out, binary.BigEndian, uint32(body.Len()+8))
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// ReadICNS 列出 ICNS 文件中的图像条目；PNG 条目读取真实尺寸，其余按类型推断。
+func ReadICNS(buf []byte) ([]ICNSEntry, error) {
+	if !isICNS(buf) {
+		return nil, apperror.UnsupportedFormat("不是有效的 ICNS 文件", nil)
+	}
+	total := int(binary.BigEndian.Uint32(buf[4:8]))
+	if total > len(buf) {
+		total = len(buf)
+	}
+	known := map[string]int{"is32": 16, "il32": 32, "ih32": 48, "it32": 128, "icp6": 64}
+	for _, item := range icnsTypes {
+		known[item.Type] = item.Size
+	}
+	entries := []ICNSEntry{}
+	for offset := 8; offset+8 <= total; {
+		kind := string(buf[offset : offset+4])
+		length := int(binary.BigEndian.Uint32(buf[offset+4 : offset+8]))
+		if length < 8 || offset+length > total {
+			return nil, apperror.InvalidInput("ICNS 文件已损坏", nil)
+		}
+		payload := buf[offset+8 : offset+length]
+		if cfg, err := png.DecodeConfig(bytes.NewReader(payload)); err == nil {
+			entries = append(entries, ICNSEntry{Type: kind, Width: cfg.Width, Height: cfg.Height})
+		} else if size, ok := known[kind]; ok {
+			entries = append(entries, ICNSEntry{Type: kind, Width: size, Height: size})
+		}
+		offset += length
+	}
+	if len(entries) == 0 {
+		return nil, apperror.InvalidInput("ICNS 文件不包含图像", nil)
+	}
+	return entries, nil
+}
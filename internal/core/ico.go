@@ -1,6 +1,9 @@
 package core
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
 	"sort"
 	"strconv"
 	"strings"
@@ -51,3 +54,38 @@ func ParseICOSizes(value string) ([]int, error) {
 	})
 	return result, nil
 }
+
+// encodeICO 以 PNG 条目写出 ICO 文件（Windows Vista 起支持），每张图像一个条目，边长不超过 256。
+func encodeICO(images []*image.NRGBA) ([]byte, error) {
+	entries := make([][]byte, 0, len(images))
+	for _, img := range images {
+		data, err := encodePNG(img)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, data)
+	}
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, [3]uint16{0, 1, uint16(len(images))})
+	offset := 6 + 16*len(images)
+	for i, img := range images {
+		// 目录项中的宽高以单字节存储，0 表示 256。
+		binary.Write(&out, binary.LittleEndian, struct {
+			Width, Height, Colors, Reserved uint8
+			Planes, BitCount                uint16
+			Size, Offset                    uint32
+		}{
+			Width:    uint8(img.Rect.Dx()),
+			Height:   uint8(img.Rect.Dy()),
+			Planes:   1,
+			BitCount: 32,
+			Size:     uint32(len(entries[i])),
+			Offset:   uint32(offset),
+		})
+		offset += len(entries[i])
+	}
+	for _, data := range entries {
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,472 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+var iconTargets = []string{"ios", "android", "pwa", "favicon", "macos"}
+
+type IconsOptions struct {
+	Targets    []string
+	Background string
+	Padding    float64
+	Name       string
+	Overwrite  bool
+	Conflict   string
+}
+
+type appIconEntry struct {
+	Size     string `json:"size"`
+	Idiom    string `json:"idiom"`
+	Filename string `json:"filename"`
+	Scale    string `json:"scale"`
+}
+
+type appIconContents struct {
+	Images []appIconEntry `json:"images"`
+	Info   struct {
+		Version int    `json:"version"`
+		Author  string `json:"author"`
+	} `json:"info"`
+}
+
+type webManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+type iconWriter struct {
+	source     *image.NRGBA
+	svg        []byte
+	background color.NRGBA
+	padding    float64
+	overwrite  bool
+	conflict   string
+	outputs    []string
+}
+
+// ParseIconTargets 解析逗号分隔的平台列表，空值表示全部平台。
+func ParseIconTargets(value string) ([]string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "all" {
+		return append([]string(nil), iconTargets...), nil
+	}
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		valid := false
+		for _, target := range iconTargets {
+			if part == target {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, apperror.InvalidArgument("不支持的图标平台: "+part, nil)
+		}
+		if _, ok := seen[part]; ok {
+			continue
+		}
+		seen[part] = struct{}{}
+		result = append(result, part)
+	}
+	if len(result) == 0 {
+		return nil, apperror.InvalidArgument("图标平台列表为空", nil)
+	}
+	return result, nil
+}
+
+// Icons 从一张 Logo 生成各平台所需的全部图标尺寸及描述文件，返回写出的文件列表。
+// 图标集文件名固定，已存在时仅在覆盖模式下替换。
+func Icons(inputPath, outputDir string, opts IconsOptions) ([]string, error) {
+	if len(opts.Targets) == 0 {
+		opts.Targets = append([]string(nil), iconTargets...)
+	}
+	if opts.Padding < 0 || opts.Padding >= 0.5 {
+		return nil, apperror.InvalidArgument("留白比例必须在 0-0.5 之间", nil)
+	}
+	background := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	if opts.Background != "" {
+		parsed, ok := parseColor(opts.Background)
+		if !ok {
+			return nil, apperror.InvalidArgument("背景颜色无效", nil)
+		}
+		background = parsed
+		background.A = 255
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	conflict := opts.Conflict
+	if conflict == "rename" {
+		conflict = ""
+	}
+	w := &iconWriter{
+		background: background,
+		padding:    opts.Padding,
+		overwrite:  opts.Overwrite,
+		conflict:   conflict,
+	}
+	switch DetectImageType(buf) {
+	case bimg.UNKNOWN:
+		return nil, apperror.UnsupportedFormat("无法识别输入格式", nil)
+	case bimg.SVG:
+		// 矢量 Logo 在 render 中按每个图标的目标尺寸栅格化，这里只校验尺寸可解析。
+		if _, _, err := SVGSize(buf); err != nil {
+			return nil, err
+		}
+		w.svg = buf
+	default:
+		w.source, err = decodeRaster(buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	name := opts.Name
+	if name == "" {
+		base := filepath.Base(inputPath)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	for _, target := range opts.Targets {
+		switch target {
+		case "ios":
+			err = w.writeIOS(filepath.Join(outputDir, "ios", "AppIcon.appiconset"))
+		case "android":
+			err = w.writeAndroid(filepath.Join(outputDir, "android"))
+		case "pwa":
+			err = w.writePWA(filepath.Join(outputDir, "web"), name)
+		case "favicon":
+			err = w.writeFavicon(filepath.Join(outputDir, "web"))
+		case "macos":
+			err = w.writeMacOS(filepath.Join(outputDir, "macos", "AppIcon.appiconset"))
+		}
+		if err != nil {
+			return w.outputs, err
+		}
+	}
+	if hasTarget(opts.Targets, "pwa") || hasTarget(opts.Targets, "favicon") {
+		if err := w.writeHTML(filepath.Join(outputDir, "web", "icons.html"), opts.Targets); err != nil {
+			return w.outputs, err
+		}
+	}
+	return w.outputs, nil
+}
+
+func hasTarget(targets []string, target string) bool {
+	for _, item := range targets {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// fit 将 Logo 等比缩放到 size×size 内；SVG 直接按该尺寸栅格化，避免放大位图。
+func (w *iconWriter) fit(size int) (*image.NRGBA, error) {
+	if w.svg == nil {
+		return resizeFrameTransform(size, size, bimg.Options{Embed: true, Enlarge: true})(w.source)
+	}
+	rendered, err := renderSVGAt(w.svg, 0, size, size)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRaster(rendered)
+}
+
+// render 将 Logo 等比缩放到 size 内（按 inset 留白），opaque 时铺底色，circle 时裁为圆形。
+func (w *iconWriter) render(size int, inset float64, opaque, circle bool) (*image.NRGBA, error) {
+	inner := maxInt(1, int(math.Round(float64(size)*(1-2*inset))))
+	fitted, err := w.fit(inner)
+	if err != nil {
+		return nil, err
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if opaque {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(w.background), image.Point{}, draw.Src)
+	}
+	offset := (size - fitted.Rect.Dx()) / 2
+	draw.Draw(canvas, fitted.Rect.Add(image.Pt(offset, (size-fitted.Rect.Dy())/2)), fitted, image.Point{}, draw.Over)
+	if circle {
+		applyCircleMask(canvas)
+	}
+	return canvas, nil
+}
+
+func (w *iconWriter) solid(size int) *image.NRGBA {
+	canvas := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(w.background), image.Point{}, draw.Src)
+	return canvas
+}
+
+func (w *iconWriter) writeFile(path string, data []byte) error {
+	path, err := applyConflict(path, w.conflict, w.overwrite)
+	if err != nil {
+		return err
+	}
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return apperror.ConfigError("无法写入输出文件", err)
+	}
+	w.outputs = append(w.outputs, path)
+	return nil
+}
+
+func (w *iconWriter) writePNG(path string, img *image.NRGBA) error {
+	data, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(path, data)
+}
+
+// writeIcon 渲染 size×size 的图标并写出 PNG，参数含义同 render。
+func (w *iconWriter) writeIcon(path string, size int, inset float64, opaque, circle bool) error {
+	img, err := w.render(size, inset, opaque, circle)
+	if err != nil {
+		return err
+	}
+	return w.writePNG(path, img)
+}
+
+func (w *iconWriter) writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return apperror.ConfigError("JSON 编码失败", err)
+	}
+	return w.writeFile(path, append(data, '\n'))
+}
+
+func (w *iconWriter) writeIOS(dir string) error {
+	specs := []struct {
+		idiom  string
+		points float64
+		scales []int
+	}{
+		{"iphone", 20, []int{2, 3}},
+		{"iphone", 29, []int{2, 3}},
+		{"iphone", 40, []int{2, 3}},
+		{"iphone", 60, []int{2, 3}},
+		{"ipad", 20, []int{1, 2}},
+		{"ipad", 29, []int{1, 2}},
+		{"ipad", 40, []int{1, 2}},
+		{"ipad", 76, []int{1, 2}},
+		{"ipad", 83.5, []int{2}},
+		{"ios-marketing", 1024, []int{1}},
+	}
+	contents := appIconContents{}
+	written := map[string]struct{}{}
+	for _, spec := range specs {
+		for _, scale := range spec.scales {
+			point := formatPoints(spec.points)
+			filename := fmt.Sprintf("Icon-App-%sx%s@%dx.png", point, point, scale)
+			if _, ok := written[filename]; !ok {
+				size := int(math.Round(spec.points * float64(scale)))
+				if err := w.writeIcon(filepath.Join(dir, filename), size, w.padding, true, false); err != nil {
+					return err
+				}
+				written[filename] = struct{}{}
+			}
+			contents.Images = append(contents.Images, appIconEntry{
+				Size:     point + "x" + point,
+				Idiom:    spec.idiom,
+				Filename: filename,
+				Scale:    fmt.Sprintf("%dx", scale),
+			})
+		}
+	}
+	contents.Info.Version = 1
+	contents.Info.Author = "image-cli"
+	return w.writeJSON(filepath.Join(dir, "Contents.json"), contents)
+}
+
+func (w *iconWriter) writeMacOS(dir string) error {
+	contents := appIconContents{}
+	for _, points := range []int{16, 32, 128, 256, 512} {
+		for _, scale := range []int{1, 2} {
+			filename := fmt.Sprintf("icon_%dx%d.png", points, points)
+			if scale == 2 {
+				filename = fmt.Sprintf("icon_%dx%d@2x.png", points, points)
+			}
+			// macOS 图标按 Big Sur 规范在四周保留约 10% 的透明边距。
+			if err := w.writeIcon(filepath.Join(dir, filename), points*scale, 0.1+w.padding*0.8, false, false); err != nil {
+				return err
+			}
+			contents.Images = append(contents.Images, appIconEntry{
+				Size:     fmt.Sprintf("%dx%d", points, points),
+				Idiom:    "mac",
+				Filename: filename,
+				Scale:    fmt.Sprintf("%dx", scale),
+			})
+		}
+	}
+	contents.Info.Version = 1
+	contents.Info.Author = "image-cli"
+	if err := w.writeJSON(filepath.Join(dir, "Contents.json"), contents); err != nil {
+		return err
+	}
+	icon, err := w.render(1024, 0.1+w.padding*0.8, false, false)
+	if err != nil {
+		return err
+	}
+	icns, err := encodeICNS(icon)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(filepath.Join(filepath.Dir(dir), "AppIcon.icns"), icns)
+}
+
+func (w *iconWriter) writeAndroid(dir string) error {
+	densities := []struct {
+		name   string
+		legacy int
+	}{
+		{"mdpi", 48},
+		{"hdpi", 72},
+		{"xhdpi", 96},
+		{"xxhdpi", 144},
+		{"xxxhdpi", 192},
+	}
+	for _, density := range densities {
+		mipmap := filepath.Join(dir, "res", "mipmap-"+density.name)
+		// 自适应图标画布为 108dp，内容需落在中间 66dp 的安全区内。
+		layer := density.legacy * 108 / 48
+		inset := (1 - 66.0/108.0) / 2
+		if err := w.writeIcon(filepath.Join(mipmap, "ic_launcher.png"), density.legacy, w.padding, true, false); err != nil {
+			return err
+		}
+		if err := w.writeIcon(filepath.Join(mipmap, "ic_launcher_round.png"), density.legacy, w.padding+0.08, true, true); err != nil {
+			return err
+		}
+		if err := w.writeIcon(filepath.Join(mipmap, "ic_launcher_foreground.png"), layer, inset+w.padding*66/108, false, false); err != nil {
+			return err
+		}
+		if err := w.writePNG(filepath.Join(mipmap, "ic_launcher_background.png"), w.solid(layer)); err != nil {
+			return err
+		}
+	}
+	adaptive := "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<adaptive-icon xmlns:android=\"http://schemas.android.com/apk/res/android\">\n    <background android:drawable=\"@mipmap/ic_launcher_background\" />\n    <foreground android:drawable=\"@mipmap/ic_launcher_foreground\" />\n</adaptive-icon>\n"
+	anydpi := filepath.Join(dir, "res", "mipmap-anydpi-v26")
+	if err := w.writeFile(filepath.Join(anydpi, "ic_launcher.xml"), []byte(adaptive)); err != nil {
+		return err
+	}
+	if err := w.writeFile(filepath.Join(anydpi, "ic_launcher_round.xml"), []byte(adaptive)); err != nil {
+		return err
+	}
+	return w.writeIcon(filepath.Join(dir, "playstore-icon.png"), 512, w.padding, true, false)
+}
+
+func (w *iconWriter) writePWA(dir, name string) error {
+	icons := []webManifestIcon{}
+	for _, size := range []int{192, 512} {
+		filename := fmt.Sprintf("icon-%d.png", size)
+		if err := w.writeIcon(filepath.Join(dir, filename), size, w.padding, false, false); err != nil {
+			return err
+		}
+		icons = append(icons, webManifestIcon{Src: "/" + filename, Sizes: fmt.Sprintf("%dx%d", size, size), Type: "image/png"})
+	}
+	for _, size := range []int{192, 512} {
+		filename := fmt.Sprintf("icon-maskable-%d.png", size)
+		// maskable 图标的安全区为直径 80% 的圆，四周各留 10%。
+		if err := w.writeIcon(filepath.Join(dir, filename), size, 0.1+w.padding*0.8, true, false); err != nil {
+			return err
+		}
+		icons = append(icons, webManifestIcon{Src: "/" + filename, Sizes: fmt.Sprintf("%dx%d", size, size), Type: "image/png", Purpose: "maskable"})
+	}
+	themeColor := fmt.Sprintf("#%02x%02x%02x", w.background.R, w.background.G, w.background.B)
+	return w.writeJSON(filepath.Join(dir, "manifest.webmanifest"), map[string]any{
+		"name":             name,
+		"short_name":       name,
+		"icons":            icons,
+		"theme_color":      themeColor,
+		"background_color": themeColor,
+	})
+}
+
+func (w *iconWriter) writeFavicon(dir string) error {
+	for _, size := range []int{16, 32} {
+		filename := fmt.Sprintf("favicon-%dx%d.png", size, size)
+		if err := w.writeIcon(filepath.Join(dir, filename), size, w.padding, false, false); err != nil {
+			return err
+		}
+	}
+	if err := w.writeIcon(filepath.Join(dir, "apple-touch-icon.png"), 180, w.padding, true, false); err != nil {
+		return err
+	}
+	sizes := []int{48, 32, 16}
+	images := make([]*image.NRGBA, 0, len(sizes))
+	for _, size := range sizes {
+		icon, err := w.render(size, w.padding, false, false)
+		if err != nil {
+			return err
+		}
+		images = append(images, icon)
+	}
+	ico, err := encodeICO(images)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(filepath.Join(dir, "favicon.ico"), ico)
+}
+
+func (w *iconWriter) writeHTML(path string, targets []string) error {
+	var b strings.Builder
+	if hasTarget(targets, "favicon") {
+		b.WriteString("<link rel=\"icon\" href=\"/favicon.ico\" sizes=\"48x48\">\n")
+		b.WriteString("<link rel=\"icon\" type=\"image/png\" sizes=\"32x32\" href=\"/favicon-32x32.png\">\n")
+		b.WriteString("<link rel=\"icon\" type=\"image/png\" sizes=\"16x16\" href=\"/favicon-16x16.png\">\n")
+		b.WriteString("<link rel=\"apple-touch-icon\" sizes=\"180x180\" href=\"/apple-touch-icon.png\">\n")
+	}
+	if hasTarget(targets, "pwa") {
+		b.WriteString("<link rel=\"manifest\" href=\"/manifest.webmanifest\">\n")
+		fmt.Fprintf(&b, "<meta name=\"theme-color\" content=\"#%02x%02x%02x\">\n", w.background.R, w.background.G, w.background.B)
+	}
+	return w.writeFile(path, []byte(b.String()))
+}
+
+func applyCircleMask(img *image.NRGBA) {
+	size := float64(img.Rect.Dx())
+	radius := size / 2
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			dx := float64(x) + 0.5 - radius
+			dy := float64(y) + 0.5 - radius
+			coverage := radius - math.Sqrt(dx*dx+dy*dy) + 0.5
+			if coverage >= 1 {
+				continue
+			}
+			offset := y*img.Stride + x*4 + 3
+			if coverage <= 0 {
+				img.Pix[offset] = 0
+				continue
+			}
+			img.Pix[offset] = uint8(float64(img.Pix[offset]) * coverage)
+		}
+	}
+}
+
+func formatPoints(points float64) string {
+	if points == math.Trunc(points) {
+		return fmt.Sprintf("%d", int(points))
+	}
+	return fmt.Sprintf("%g", points)
+}
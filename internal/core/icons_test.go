@@ -0,0 +1,49 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIconsWritesFaviconICO(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 64, 48)
+	outDir := filepath.Join(dir, "icons")
+	if _, err := Icons(input, outDir, IconsOptions{Targets: []string{"favicon"}}); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(filepath.Join(outDir, "web", "favicon.ico"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) < 6 || binary.LittleEndian.Uint16(buf[2:4]) != 1 {
+		t.Fatalf("favicon.ico header = % x", buf[:minInt(len(buf), 6)])
+	}
+	count := int(binary.LittleEndian.Uint16(buf[4:6]))
+	want := []int{48, 32, 16}
+	if count != len(want) {
+		t.Fatalf("favicon.ico has %d entries, want %d", count, len(want))
+	}
+	for i, size := range want {
+		entry := buf[6+16*i : 6+16*(i+1)]
+		length := int(binary.LittleEndian.Uint32(entry[8:12]))
+		offset := int(binary.LittleEndian.Uint32(entry[12:16]))
+		if int(entry[0]) != size || int(entry[1]) != size {
+			t.Errorf("entry %d size = %dx%d, want %d", i, entry[0], entry[1], size)
+		}
+		if offset+length > len(buf) {
+			t.Fatalf("entry %d exceeds file: offset %d length %d", i, offset, length)
+		}
+		img, err := png.Decode(bytes.NewReader(buf[offset : offset+length]))
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if img.Bounds().Dx() != size || img.Bounds().Dy() != size {
+			t.Errorf("entry %d PNG bounds = %v, want %dx%d", i, img.Bounds(), size, size)
+		}
+	}
+}
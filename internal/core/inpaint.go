@@ -0,0 +1,374 @@
+package core
+
+import (
+	"container/heap"
+	"image"
+	"math"
+	"os"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// Inpainter 调用外部修复服务：pngData 为解码后的原图 PNG，mask 为同尺寸的 PNG，白色为需要修复的区域。
+type Inpainter func(pngData, mask []byte) ([]byte, error)
+
+// RemoveWatermarkOptions 为去水印参数。水印区域由 MaskPath（白色为水印）与 Rects 共同确定，
+// 并向外扩展 Dilate 像素；Inpainter 为空时使用本地 Telea 快速行进修复。
+type RemoveWatermarkOptions struct {
+	MaskPath  string
+	Rects     []image.Rectangle
+	Dilate    int
+	Radius    int
+	Format    string
+	Quality   int
+	Conflict  string
+	Inpainter Inpainter
+}
+
+// RemoveWatermark 修复水印区域并保持其余像素不变。
+func RemoveWatermark(inputPath, outputArg string, opts RemoveWatermarkOptions) (string, error) {
+	if opts.MaskPath == "" && len(opts.Rects) == 0 {
+		return "", apperror.InvalidArgument("必须通过 --mask 或 --rect 指定水印区域", nil)
+	}
+	if opts.Dilate < 0 || opts.Dilate > 50 {
+		return "", apperror.InvalidArgument("遮罩扩展必须在 0-50 之间", nil)
+	}
+	if opts.Radius < 1 || opts.Radius > 20 {
+		return "", apperror.InvalidArgument("修复半径必须在 1-20 之间", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: opts.Format,
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	mask, err := buildInpaintMask(img.Rect.Dx(), img.Rect.Dy(), opts.MaskPath, opts.Rects)
+	if err != nil {
+		return "", err
+	}
+	mask = dilateMask(mask, img.Rect.Dx(), img.Rect.Dy(), opts.Dilate)
+	masked := 0
+	for _, m := range mask {
+		if m {
+			masked++
+		}
+	}
+	if masked == 0 {
+		return "", apperror.InvalidArgument("水印区域为空", nil)
+	}
+	if masked == len(mask) {
+		return "", apperror.InvalidArgument("水印区域覆盖了整张图像", nil)
+	}
+	if opts.Inpainter != nil {
+		pngBuf, err := encodePNG(img)
+		if err != nil {
+			return "", err
+		}
+		maskBuf, err := encodePNG(maskImage(mask, img.Rect.Dx(), img.Rect.Dy()))
+		if err != nil {
+			return "", err
+		}
+		result, err := opts.Inpainter(pngBuf, maskBuf)
+		if err != nil {
+			return "", err
+		}
+		repaired, err := decodeRaster(result)
+		if err != nil {
+			return "", apperror.New("E703", "无法解析修复结果", err.Error(), err)
+		}
+		if repaired.Rect.Dx() != img.Rect.Dx() || repaired.Rect.Dy() != img.Rect.Dy() {
+			repaired = scaleNRGBA(repaired, img.Rect.Dx(), img.Rect.Dy())
+		}
+		// 仅替换遮罩内像素，避免服务对其余区域的重新编码造成画质损失。
+		for i, m := range mask {
+			if m {
+				copy(img.Pix[i*4:i*4+4], repaired.Pix[repaired.PixOffset(i%img.Rect.Dx(), i/img.Rect.Dx()):])
+			}
+		}
+	} else {
+		teleaInpaint(img, mask, opts.Radius)
+	}
+	newImage, err := encodeRaster(img, outType, opts.Quality)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+// buildInpaintMask 合并遮罩图与矩形区域。遮罩图按亮度判断（带透明通道时取不透明部分），尺寸不同时缩放到原图大小。
+func buildInpaintMask(width, height int, maskPath string, rects []image.Rectangle) ([]bool, error) {
+	mask := make([]bool, width*height)
+	if maskPath != "" {
+		maskBuf, err := os.ReadFile(maskPath)
+		if err != nil {
+			return nil, apperror.InvalidInput("无法读取遮罩文件", err)
+		}
+		maskBuf, _, err = NormalizeImage(maskBuf)
+		if err != nil {
+			return nil, err
+		}
+		maskImg, err := decodeRaster(maskBuf)
+		if err != nil {
+			return nil, err
+		}
+		if maskImg.Rect.Dx() != width || maskImg.Rect.Dy() != height {
+			maskImg = scaleNRGBA(maskImg, width, height)
+		}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				c := maskImg.NRGBAAt(x, y)
+				luma := (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+				mask[y*width+x] = luma*int(c.A)/255 >= 128
+			}
+		}
+	}
+	bounds := image.Rect(0, 0, width, height)
+	for _, rect := range rects {
+		rect = rect.Intersect(bounds)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				mask[y*width+x] = true
+			}
+		}
+	}
+	return mask, nil
+}
+
+// dilateMask 以方形结构元素扩展遮罩，覆盖水印边缘的抗锯齿像素。
+func dilateMask(mask []bool, width, height, radius int) []bool {
+	if radius <= 0 {
+		return mask
+	}
+	horizontal := make([]bool, len(mask))
+	for y := 0; y < height; y++ {
+		last := -radius - 1
+		for x := 0; x < width; x++ {
+			if mask[y*width+x] {
+				last = x
+			}
+			horizontal[y*width+x] = x-last <= radius
+		}
+		last = width + radius
+		for x := width - 1; x >= 0; x-- {
+			if mask[y*width+x] {
+				last = x
+			}
+			if last-x <= radius {
+				horizontal[y*width+x] = true
+			}
+		}
+	}
+	out := make([]bool, len(mask))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			for dy := maxInt(y-radius, 0); dy <= minInt(y+radius, height-1); dy++ {
+				if horizontal[dy*width+x] {
+					out[y*width+x] = true
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+func maskImage(mask []bool, width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, m := range mask {
+		v := uint8(0)
+		if m {
+			v = 255
+		}
+		img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2], img.Pix[i*4+3] = v, v, v, 255
+	}
+	return img
+}
+
+const (
+	fmmKnown = iota
+	fmmBand
+	fmmInside
+)
+
+type fmmPoint struct {
+	t    float64
+	x, y int
+}
+
+type fmmHeap []fmmPoint
+
+func (h fmmHeap) Len() int            { return len(h) }
+func (h fmmHeap) Less(i, j int) bool  { return h[i].t < h[j].t }
+func (h fmmHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *fmmHeap) Push(v interface{}) { *h = append(*h, v.(fmmPoint)) }
+func (h *fmmHeap) Pop() interface{} {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+// teleaInpaint 按 Telea (2004) 快速行进法由外向内修复遮罩区域：每个像素取半径内已知像素的加权平均，
+// 权重综合方向（沿边界法线）、距离与等距线层级，适合文字、角标等细小水印。
+func teleaInpaint(img *image.NRGBA, mask []bool, radius int) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	flags := make([]uint8, width*height)
+	dist := make([]float64, width*height)
+	for i, m := range mask {
+		if m {
+			flags[i] = fmmInside
+			dist[i] = math.Inf(1)
+		}
+	}
+	neighbors := [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	inBounds := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < width && y < height
+	}
+	band := &fmmHeap{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if flags[y*width+x] != fmmKnown {
+				continue
+			}
+			for _, n := range neighbors {
+				nx, ny := x+n[0], y+n[1]
+				if inBounds(nx, ny) && flags[ny*width+nx] == fmmInside {
+					flags[y*width+x] = fmmBand
+					heap.Push(band, fmmPoint{t: 0, x: x, y: y})
+					break
+				}
+			}
+		}
+	}
+	known := func(x, y int) bool {
+		return inBounds(x, y) && flags[y*width+x] != fmmInside
+	}
+	// solve 求解两相邻方向上的程函方程 |∇T| = 1。
+	solve := func(x1, y1, x2, y2 int) float64 {
+		k1, k2 := known(x1, y1), known(x2, y2)
+		switch {
+		case k1 && k2:
+			t1, t2 := dist[y1*width+x1], dist[y2*width+x2]
+			d := 2 - (t1-t2)*(t1-t2)
+			if d > 0 {
+				s := (t1 + t2 + math.Sqrt(d)) / 2
+				if s >= t1 && s >= t2 {
+					return s
+				}
+			}
+			return 1 + math.Min(t1, t2)
+		case k1:
+			return 1 + dist[y1*width+x1]
+		case k2:
+			return 1 + dist[y2*width+x2]
+		}
+		return math.Inf(1)
+	}
+	gradient := func(x, y int) (float64, float64) {
+		axis := func(ax, ay, bx, by int) float64 {
+			t := dist[y*width+x]
+			ka, kb := known(ax, ay), known(bx, by)
+			switch {
+			case ka && kb:
+				return (dist[by*width+bx] - dist[ay*width+ax]) / 2
+			case kb:
+				return dist[by*width+bx] - t
+			case ka:
+				return t - dist[ay*width+ax]
+			}
+			return 0
+		}
+		return axis(x-1, y, x+1, y), axis(x, y-1, x, y+1)
+	}
+	fill := func(x, y int) {
+		gx, gy := gradient(x, y)
+		if norm := math.Hypot(gx, gy); norm > 0 {
+			gx, gy = gx/norm, gy/norm
+		}
+		t := dist[y*width+x]
+		var acc [4]float64
+		total := 0.0
+		for qy := maxInt(y-radius, 0); qy <= minInt(y+radius, height-1); qy++ {
+			for qx := maxInt(x-radius, 0); qx <= minInt(x+radius, width-1); qx++ {
+				if flags[qy*width+qx] == fmmInside {
+					continue
+				}
+				rx, ry := float64(x-qx), float64(y-qy)
+				lenSq := rx*rx + ry*ry
+				if lenSq == 0 || lenSq > float64(radius*radius) {
+					continue
+				}
+				direction := math.Abs(rx*gx+ry*gy) / math.Sqrt(lenSq)
+				if direction < 1e-6 {
+					direction = 1e-6
+				}
+				level := 1 / (1 + math.Abs(dist[qy*width+qx]-t))
+				weight := direction * level / lenSq
+				offset := img.PixOffset(qx, qy)
+				for c := 0; c < 4; c++ {
+					acc[c] += float64(img.Pix[offset+c]) * weight
+				}
+				total += weight
+			}
+		}
+		if total == 0 {
+			return
+		}
+		offset := img.PixOffset(x, y)
+		for c := 0; c < 4; c++ {
+			img.Pix[offset+c] = clampByte(acc[c] / total)
+		}
+	}
+	for band.Len() > 0 {
+		p := heap.Pop(band).(fmmPoint)
+		idx := p.y*width + p.x
+		if flags[idx] == fmmKnown {
+			continue
+		}
+		flags[idx] = fmmKnown
+		for _, n := range neighbors {
+			nx, ny := p.x+n[0], p.y+n[1]
+			if !inBounds(nx, ny) || flags[ny*width+nx] != fmmInside {
+				continue
+			}
+			t := math.Min(
+				math.Min(solve(nx-1, ny, nx, ny-1), solve(nx+1, ny, nx, ny-1)),
+				math.Min(solve(nx-1, ny, nx, ny+1), solve(nx+1, ny, nx, ny+1)),
+			)
+			dist[ny*width+nx] = t
+			flags[ny*width+nx] = fmmBand
+			fill(nx, ny)
+			heap.Push(band, fmmPoint{t: t, x: nx, y: ny})
+		}
+	}
+}
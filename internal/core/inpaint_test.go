@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// smoothGradient 生成平滑的双向渐变作为修复的真值。
+func smoothGradient(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(40 + x*160/width),
+				G: uint8(60 + y*150/height),
+				B: uint8(200 - (x+y)*100/(width+height)),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+// paintWatermark 在真值副本上画出实心矩形，模拟需要去除的水印。
+func paintWatermark(truth *image.NRGBA, rect image.Rectangle) *image.NRGBA {
+	marked := cloneNRGBA(truth)
+	draw.Draw(marked, rect, image.NewUniform(color.NRGBA{R: 255, G: 255, B: 255, A: 255}), image.Point{}, draw.Src)
+	return marked
+}
+
+// repairError 返回遮罩区域内各通道相对真值的平均与最大绝对误差。
+func repairError(got, truth *image.NRGBA, rect image.Rectangle) (float64, int) {
+	total, worst, count := 0, 0, 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			g, w := got.NRGBAAt(x, y), truth.NRGBAAt(x, y)
+			for _, d := range []int{int(g.R) - int(w.R), int(g.G) - int(w.G), int(g.B) - int(w.B)} {
+				if d < 0 {
+					d = -d
+				}
+				total += d
+				worst = maxInt(worst, d)
+				count++
+			}
+		}
+	}
+	return float64(total) / float64(count), worst
+}
+
+func rectMask(rect image.Rectangle, width, height int) []bool {
+	mask := make([]bool, width*height)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			mask[y*width+x] = true
+		}
+	}
+	return mask
+}
+
+func TestTeleaInpaintRestoresGradient(t *testing.T) {
+	truth := smoothGradient(96, 64)
+	rect := image.Rect(30, 24, 62, 36)
+	img := paintWatermark(truth, rect)
+	teleaInpaint(img, rectMask(rect, 96, 64), 5)
+	mean, worst := repairError(img, truth, rect)
+	t.Logf("mean error %.2f, max error %d", mean, worst)
+	if mean > 4 || worst > 16 {
+		t.Errorf("repair error mean %.2f max %d exceeds bound (4, 16)", mean, worst)
+	}
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 96; x++ {
+			if !image.Pt(x, y).In(rect) && img.NRGBAAt(x, y) != truth.NRGBAAt(x, y) {
+				t.Fatalf("pixel (%d,%d) outside the mask changed", x, y)
+			}
+		}
+	}
+}
+
+func writeNRGBA(t *testing.T, path string, img *image.NRGBA) {
+	t.Helper()
+	buf, err := encodePNG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readNRGBA(t *testing.T, path string) *image.NRGBA {
+	t.Helper()
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestRemoveWatermarkLocal(t *testing.T) {
+	dir := t.TempDir()
+	truth := smoothGradient(96, 64)
+	rect := image.Rect(30, 24, 62, 36)
+	input := filepath.Join(dir, "marked.png")
+	writeNRGBA(t, input, paintWatermark(truth, rect))
+	outPath, err := RemoveWatermark(input, filepath.Join(dir, "clean.png"), RemoveWatermarkOptions{
+		Rects:  []image.Rectangle{rect},
+		Radius: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := readNRGBA(t, outPath)
+	mean, worst := repairError(out, truth, rect)
+	if mean > 4 || worst > 16 {
+		t.Errorf("repair error mean %.2f max %d exceeds bound (4, 16)", mean, worst)
+	}
+}
+
+func TestRemoveWatermarkInpainterReplacesOnlyMaskedPixels(t *testing.T) {
+	dir := t.TempDir()
+	truth := smoothGradient(48, 32)
+	rect := image.Rect(10, 8, 20, 14)
+	marked := paintWatermark(truth, rect)
+	input := filepath.Join(dir, "marked.png")
+	writeNRGBA(t, input, marked)
+	green := color.NRGBA{G: 255, A: 255}
+	var sentImage, sentMask []byte
+	outPath, err := RemoveWatermark(input, filepath.Join(dir, "clean.png"), RemoveWatermarkOptions{
+		Rects:  []image.Rectangle{rect},
+		Dilate: 1,
+		Radius: 5,
+		Inpainter: func(pngData, mask []byte) ([]byte, error) {
+			sentImage, sentMask = pngData, mask
+			// 服务返回整幅改动过的图像，只有遮罩内的像素应被采用。
+			result := image.NewNRGBA(image.Rect(0, 0, 48, 32))
+			draw.Draw(result, result.Rect, image.NewUniform(green), image.Point{}, draw.Src)
+			return encodePNG(result)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := decodeRaster(sentImage)
+	if err != nil || !bytes.Equal(sent.Pix, marked.Pix) {
+		t.Fatalf("inpainter did not receive the decoded input image (%v)", err)
+	}
+	maskImg, err := decodeRaster(sentMask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dilated := rect.Inset(-1)
+	out := readNRGBA(t, outPath)
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 48; x++ {
+			inMask := image.Pt(x, y).In(dilated)
+			if got := maskImg.NRGBAAt(x, y).R == 255; got != inMask {
+				t.Fatalf("mask pixel (%d,%d) = %v, want %v", x, y, got, inMask)
+			}
+			want := marked.NRGBAAt(x, y)
+			if inMask {
+				want = green
+			}
+			if got := out.NRGBAAt(x, y); got != want {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
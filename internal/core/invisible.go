@@ -0,0 +1,430 @@
+package core
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// 隐形水印在固定尺寸的亮度载体上做 8x8 DCT，用一对中低频系数的大小关系表示比特，
+// 再把载体的改动插值回原图。载体尺寸固定，因此缩放与 JPEG 重压缩后仍可检测。
+const (
+	invisibleCarrierSize     = 320
+	invisibleBlockSize       = 8
+	invisibleMaxPayload      = 16
+	invisibleFrameBytes      = invisibleMaxPayload + 2
+	invisibleFrameBits       = invisibleFrameBytes * 8
+	invisibleDefaultStrength = 12.0
+	invisibleSeed            = 0x5eed1234
+)
+
+type InvisibleWatermarkOptions struct {
+	Payload  string
+	Strength float64
+	Quality  int
+	Conflict string
+}
+
+type DetectResult struct {
+	Found      bool
+	Payload    string
+	Confidence float64
+}
+
+func InvisibleWatermark(inputPath, outputArg string, opts InvisibleWatermarkOptions) (string, error) {
+	frame, err := invisibleFrame(opts.Payload)
+	if err != nil {
+		return "", err
+	}
+	if opts.Strength < 0 {
+		return "", apperror.InvalidArgument("隐形水印强度不能为负数", nil)
+	}
+	strength := opts.Strength
+	if strength == 0 {
+		strength = 1
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	inputFormat := FormatFromImageType(inputType)
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   inputFormat,
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	// 小于载体的图像会把载体上的改动平滑掉，重压缩后无法可靠检测。
+	if img.Rect.Dx() < invisibleCarrierSize || img.Rect.Dy() < invisibleCarrierSize {
+		return "", apperror.InvalidArgument(fmt.Sprintf("隐形水印要求图像宽高均不小于 %d 像素", invisibleCarrierSize), nil)
+	}
+	embedInvisible(img, frame, invisibleDefaultStrength*strength)
+	newImage, err := encodeRaster(img, outType, opts.Quality)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func DetectWatermark(inputPath string) (DetectResult, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return DetectResult{}, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	if DetectImageType(buf) == bimg.UNKNOWN {
+		return DetectResult{}, apperror.UnsupportedFormat("无法识别输入格式", nil)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return DetectResult{}, err
+	}
+	bits, confidence := extractInvisible(img)
+	payload, ok := parseInvisibleFrame(bits)
+	return DetectResult{Found: ok, Payload: payload, Confidence: confidence}, nil
+}
+
+func invisibleFrame(payload string) ([]bool, error) {
+	if payload == "" {
+		return nil, apperror.InvalidArgument("隐形水印内容不能为空", nil)
+	}
+	if len(payload) > invisibleMaxPayload {
+		return nil, apperror.InvalidArgument("隐形水印内容最多 16 字节", nil)
+	}
+	frame := make([]byte, invisibleFrameBytes)
+	frame[0] = byte(len(payload))
+	copy(frame[1:], payload)
+	frame[invisibleFrameBytes-1] = crc8(frame[:invisibleFrameBytes-1])
+	whiten(frame)
+	bits := make([]bool, invisibleFrameBits)
+	for i := range bits {
+		bits[i] = frame[i/8]&(0x80>>(i%8)) != 0
+	}
+	return bits, nil
+}
+
+func parseInvisibleFrame(bits []bool) (string, bool) {
+	frame := make([]byte, invisibleFrameBytes)
+	for i, bit := range bits {
+		if bit {
+			frame[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	whiten(frame)
+	length := int(frame[0])
+	if length == 0 || length > invisibleMaxPayload {
+		return "", false
+	}
+	if crc8(frame[:invisibleFrameBytes-1]) != frame[invisibleFrameBytes-1] {
+		return "", false
+	}
+	return string(frame[1 : 1+length]), true
+}
+
+func whiten(frame []byte) {
+	state := uint32(invisibleSeed)
+	for i := range frame {
+		state = state*1664525 + 1013904223
+		frame[i] ^= byte(state >> 24)
+	}
+}
+
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// invisibleBlockBits 把载体上的每个块映射到帧内的比特序号，打乱顺序让重复的比特分散到整幅图像。
+func invisibleBlockBits() []int {
+	blocksPerSide := invisibleCarrierSize / invisibleBlockSize
+	count := blocksPerSide * blocksPerSide
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	state := uint32(invisibleSeed)
+	for i := count - 1; i > 0; i-- {
+		state = state*1664525 + 1013904223
+		j := int(state>>8) % (i + 1)
+		order[i], order[j] = order[j], order[i]
+	}
+	bits := make([]int, count)
+	for i, block := range order {
+		bits[block] = i % invisibleFrameBits
+	}
+	return bits
+}
+
+func embedInvisible(img *image.NRGBA, bits []bool, strength float64) {
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+	carrier := resamplePlane(lumaPlane(img), width, height, invisibleCarrierSize, invisibleCarrierSize, resampleAreaLine)
+	original := append([]float64(nil), carrier...)
+	blockBits := invisibleBlockBits()
+	blocksPerSide := invisibleCarrierSize / invisibleBlockSize
+	var block [invisibleBlockSize * invisibleBlockSize]float64
+	for index, bitIndex := range blockBits {
+		bx := (index % blocksPerSide) * invisibleBlockSize
+		by := (index / blocksPerSide) * invisibleBlockSize
+		readBlock(carrier, invisibleCarrierSize, bx, by, block[:])
+		coeffs := dct8(block[:])
+		c1, c2 := coeffs[1*8+2], coeffs[2*8+1]
+		diff := c1 - c2
+		target := strength
+		if !bits[bitIndex] {
+			target = -strength
+		}
+		if (target > 0 && diff < target) || (target < 0 && diff > target) {
+			shift := (target - diff) / 2
+			coeffs[1*8+2] = c1 + shift
+			coeffs[2*8+1] = c2 - shift
+		}
+		writeBlock(carrier, invisibleCarrierSize, bx, by, idct8(coeffs))
+	}
+	for i := range carrier {
+		carrier[i] -= original[i]
+	}
+	delta := resamplePlane(carrier, invisibleCarrierSize, invisibleCarrierSize, width, height, resampleBilinearLine)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			d := delta[y*width+x]
+			offset := y*img.Stride + x*4
+			for c := 0; c < 3; c++ {
+				img.Pix[offset+c] = clampByte(float64(img.Pix[offset+c]) + d)
+			}
+		}
+	}
+}
+
+func extractInvisible(img *image.NRGBA) ([]bool, float64) {
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+	carrier := resamplePlane(lumaPlane(img), width, height, invisibleCarrierSize, invisibleCarrierSize, resampleAreaLine)
+	blockBits := invisibleBlockBits()
+	blocksPerSide := invisibleCarrierSize / invisibleBlockSize
+	votes := make([]float64, invisibleFrameBits)
+	signs := make([][]bool, invisibleFrameBits)
+	var block [invisibleBlockSize * invisibleBlockSize]float64
+	for index, bitIndex := range blockBits {
+		bx := (index % blocksPerSide) * invisibleBlockSize
+		by := (index / blocksPerSide) * invisibleBlockSize
+		readBlock(carrier, invisibleCarrierSize, bx, by, block[:])
+		coeffs := dct8(block[:])
+		diff := coeffs[1*8+2] - coeffs[2*8+1]
+		votes[bitIndex] += diff
+		signs[bitIndex] = append(signs[bitIndex], diff > 0)
+	}
+	bits := make([]bool, invisibleFrameBits)
+	agreement := 0.0
+	for i := range bits {
+		bits[i] = votes[i] > 0
+		agree := 0
+		for _, sign := range signs[i] {
+			if sign == bits[i] {
+				agree++
+			}
+		}
+		if len(signs[i]) > 0 {
+			agreement += float64(agree) / float64(len(signs[i]))
+		}
+	}
+	agreement /= float64(invisibleFrameBits)
+	confidence := (agreement - 0.5) * 2
+	if confidence < 0 {
+		confidence = 0
+	}
+	return bits, confidence
+}
+
+func lumaPlane(img *image.NRGBA) []float64 {
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+	plane := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := y*img.Stride + x*4
+			r := float64(img.Pix[offset])
+			g := float64(img.Pix[offset+1])
+			b := float64(img.Pix[offset+2])
+			plane[y*width+x] = 0.299*r + 0.587*g + 0.114*b
+		}
+	}
+	return plane
+}
+
+func readBlock(plane []float64, stride, bx, by int, block []float64) {
+	for y := 0; y < invisibleBlockSize; y++ {
+		copy(block[y*invisibleBlockSize:(y+1)*invisibleBlockSize], plane[(by+y)*stride+bx:])
+	}
+}
+
+func writeBlock(plane []float64, stride, bx, by int, block []float64) {
+	for y := 0; y < invisibleBlockSize; y++ {
+		copy(plane[(by+y)*stride+bx:(by+y)*stride+bx+invisibleBlockSize], block[y*invisibleBlockSize:(y+1)*invisibleBlockSize])
+	}
+}
+
+var dctTable = func() [8][8]float64 {
+	var table [8][8]float64
+	for u := 0; u < 8; u++ {
+		scale := math.Sqrt(2.0 / 8.0)
+		if u == 0 {
+			scale = math.Sqrt(1.0 / 8.0)
+		}
+		for x := 0; x < 8; x++ {
+			table[u][x] = scale * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16)
+		}
+	}
+	return table
+}()
+
+func dct8(block []float64) []float64 {
+	var tmp [64]float64
+	for y := 0; y < 8; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < 8; x++ {
+				sum += dctTable[u][x] * block[y*8+x]
+			}
+			tmp[y*8+u] = sum
+		}
+	}
+	out := make([]float64, 64)
+	for u := 0; u < 8; u++ {
+		for v := 0; v < 8; v++ {
+			sum := 0.0
+			for y := 0; y < 8; y++ {
+				sum += dctTable[v][y] * tmp[y*8+u]
+			}
+			out[v*8+u] = sum
+		}
+	}
+	return out
+}
+
+func idct8(coeffs []float64) []float64 {
+	var tmp [64]float64
+	for v := 0; v < 8; v++ {
+		for x := 0; x < 8; x++ {
+			sum := 0.0
+			for u := 0; u < 8; u++ {
+				sum += dctTable[u][x] * coeffs[v*8+u]
+			}
+			tmp[v*8+x] = sum
+		}
+	}
+	out := make([]float64, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			sum := 0.0
+			for v := 0; v < 8; v++ {
+				sum += dctTable[v][y] * tmp[v*8+x]
+			}
+			out[y*8+x] = sum
+		}
+	}
+	return out
+}
+
+func resamplePlane(src []float64, sw, sh, dw, dh int, line func([]float64, int) []float64) []float64 {
+	rows := make([]float64, dw*sh)
+	for y := 0; y < sh; y++ {
+		copy(rows[y*dw:(y+1)*dw], line(src[y*sw:(y+1)*sw], dw))
+	}
+	out := make([]float64, dw*dh)
+	column := make([]float64, sh)
+	for x := 0; x < dw; x++ {
+		for y := 0; y < sh; y++ {
+			column[y] = rows[y*dw+x]
+		}
+		resampled := line(column, dh)
+		for y := 0; y < dh; y++ {
+			out[y*dw+x] = resampled[y]
+		}
+	}
+	return out
+}
+
+func resampleAreaLine(src []float64, dn int) []float64 {
+	sn := len(src)
+	out := make([]float64, dn)
+	scale := float64(sn) / float64(dn)
+	for d := 0; d < dn; d++ {
+		start := float64(d) * scale
+		end := start + scale
+		sum := 0.0
+		for s := int(start); s < sn && float64(s) < end; s++ {
+			overlap := math.Min(end, float64(s+1)) - math.Max(start, float64(s))
+			if overlap > 0 {
+				sum += overlap * src[s]
+			}
+		}
+		out[d] = sum / scale
+	}
+	return out
+}
+
+func resampleBilinearLine(src []float64, dn int) []float64 {
+	sn := len(src)
+	out := make([]float64, dn)
+	for d := 0; d < dn; d++ {
+		pos := (float64(d)+0.5)*float64(sn)/float64(dn) - 0.5
+		if pos < 0 {
+			pos = 0
+		}
+		i0 := int(pos)
+		if i0 >= sn-1 {
+			out[d] = src[sn-1]
+			continue
+		}
+		t := pos - float64(i0)
+		out[d] = src[i0]*(1-t) + src[i0+1]*t
+	}
+	return out
+}
+
+func clampByte(value float64) uint8 {
+	if value <= 0 {
+		return 0
+	}
+	if value >= 255 {
+		return 255
+	}
+	return uint8(value + 0.5)
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// writeGradientPNG 写入一张平滑渐变图，平坦区域是隐形水印最难保留的情形。
+func writeGradientPNG(t *testing.T, dir string, width, height int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(60 + x*140/width),
+				G: uint8(90 + y*120/height),
+				B: uint8(150 - x*60/width),
+				A: 255,
+			})
+		}
+	}
+	buf, err := encodePNG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "input.png")
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInvisibleWatermarkSurvivesRecompress(t *testing.T) {
+	for _, size := range []image.Point{{X: invisibleCarrierSize, Y: invisibleCarrierSize}, {X: 1024, Y: 768}} {
+		dir := t.TempDir()
+		input := writeGradientPNG(t, dir, size.X, size.Y)
+		marked, err := InvisibleWatermark(input, filepath.Join(dir, "marked.jpg"), InvisibleWatermarkOptions{Payload: "ACME-1234", Quality: 92})
+		if err != nil {
+			t.Fatalf("%v: InvisibleWatermark: %v", size, err)
+		}
+		recompressed, err := Compress(marked, filepath.Join(dir, "recompressed.jpg"), CompressOptions{Quality: 75})
+		if err != nil {
+			t.Fatalf("%v: Compress: %v", size, err)
+		}
+		result, err := DetectWatermark(recompressed)
+		if err != nil {
+			t.Fatalf("%v: DetectWatermark: %v", size, err)
+		}
+		if !result.Found || result.Payload != "ACME-1234" {
+			t.Fatalf("%v: detect after recompress = %+v", size, result)
+		}
+		if result.Confidence < 0.9 {
+			t.Errorf("%v: confidence %.2f, want >= 0.9", size, result.Confidence)
+		}
+	}
+}
+
+func TestInvisibleWatermarkSurvivesResize(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 1024, 768)
+	marked, err := InvisibleWatermark(input, filepath.Join(dir, "marked.png"), InvisibleWatermarkOptions{Payload: "ACME-1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resized, err := Resize(marked, filepath.Join(dir, "resized.jpg"), ResizeOptions{Width: "800", KeepRatio: true, Quality: 85})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(resized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, err := bimg.Size(buf); err != nil || size.Width != 800 {
+		t.Fatalf("resized size = %+v, %v; want width 800", size, err)
+	}
+	result, err := DetectWatermark(resized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.Payload != "ACME-1234" {
+		t.Fatalf("detect after resize = %+v", result)
+	}
+}
+
+func TestInvisibleWatermarkRejectsSmallImages(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 300, 200)
+	_, err := InvisibleWatermark(input, filepath.Join(dir, "marked.jpg"), InvisibleWatermarkOptions{Payload: "ACME-1234"})
+	appErr, ok := err.(*apperror.AppError)
+	if !ok || appErr.Code != "E007" {
+		t.Fatalf("InvisibleWatermark(300x200) error = %v, want E007", err)
+	}
+}
+
+func TestDetectWatermarkUnmarked(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 640, 480)
+	result, err := DetectWatermark(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Found {
+		t.Fatalf("unmarked image detected as %+v", result)
+	}
+}
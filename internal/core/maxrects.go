@@ -0,0 +1,136 @@
+package core
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// maxRectsBin 实现 MaxRects 装箱（Best Short Side Fit），高度不设上限。
+type maxRectsBin struct {
+	width int
+	free  []image.Rectangle
+}
+
+func newMaxRectsBin(width, height int) *maxRectsBin {
+	return &maxRectsBin{width: width, free: []image.Rectangle{image.Rect(0, 0, width, height)}}
+}
+
+func (b *maxRectsBin) insert(w, h int) (image.Point, bool) {
+	bestShort, bestLong := math.MaxInt, math.MaxInt
+	var best image.Rectangle
+	found := false
+	for _, rect := range b.free {
+		if rect.Dx() < w || rect.Dy() < h {
+			continue
+		}
+		leftoverX := rect.Dx() - w
+		leftoverY := rect.Dy() - h
+		short := minInt(leftoverX, leftoverY)
+		long := maxInt(leftoverX, leftoverY)
+		if short < bestShort || short == bestShort && long < bestLong {
+			best = image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+w, rect.Min.Y+h)
+			bestShort, bestLong = short, long
+			found = true
+		}
+	}
+	if !found {
+		return image.Point{}, false
+	}
+	b.place(best)
+	return best.Min, true
+}
+
+func (b *maxRectsBin) place(used image.Rectangle) {
+	next := make([]image.Rectangle, 0, len(b.free)+4)
+	for _, rect := range b.free {
+		if !rect.Overlaps(used) {
+			next = append(next, rect)
+			continue
+		}
+		if used.Min.X > rect.Min.X {
+			next = append(next, image.Rect(rect.Min.X, rect.Min.Y, used.Min.X, rect.Max.Y))
+		}
+		if used.Max.X < rect.Max.X {
+			next = append(next, image.Rect(used.Max.X, rect.Min.Y, rect.Max.X, rect.Max.Y))
+		}
+		if used.Min.Y > rect.Min.Y {
+			next = append(next, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < rect.Max.Y {
+			next = append(next, image.Rect(rect.Min.X, used.Max.Y, rect.Max.X, rect.Max.Y))
+		}
+	}
+	pruned := make([]image.Rectangle, 0, len(next))
+	for i, rect := range next {
+		contained := false
+		for j, other := range next {
+			if i != j && rect.In(other) && (rect != other || i > j) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			pruned = append(pruned, rect)
+		}
+	}
+	b.free = pruned
+}
+
+// packRects 尝试多个候选宽度，返回总面积最小（相同时更接近正方形）的布局。
+func packRects(sizes []image.Point) ([]image.Point, int, int) {
+	order := make([]int, len(sizes))
+	maxW, totalH, area := 0, 0, 0
+	for i, size := range sizes {
+		order[i] = i
+		maxW = maxInt(maxW, size.X)
+		totalH += size.Y
+		area += size.X * size.Y
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := sizes[order[i]], sizes[order[j]]
+		if a.Y != b.Y {
+			return a.Y > b.Y
+		}
+		return a.X > b.X
+	})
+	side := int(math.Ceil(math.Sqrt(float64(area))))
+	candidates := []int{maxW}
+	for _, factor := range []float64{0.8, 1, 1.1, 1.25, 1.5, 2} {
+		candidates = append(candidates, maxInt(maxW, int(float64(side)*factor)))
+	}
+	var bestPos []image.Point
+	bestW, bestH := 0, 0
+	for _, width := range candidates {
+		bin := newMaxRectsBin(width, totalH)
+		positions := make([]image.Point, len(sizes))
+		usedW, usedH := 0, 0
+		for _, index := range order {
+			pos, ok := bin.insert(sizes[index].X, sizes[index].Y)
+			if !ok {
+				usedW = -1
+				break
+			}
+			positions[index] = pos
+			usedW = maxInt(usedW, pos.X+sizes[index].X)
+			usedH = maxInt(usedH, pos.Y+sizes[index].Y)
+		}
+		if usedW < 0 {
+			continue
+		}
+		better := bestPos == nil ||
+			usedW*usedH < bestW*bestH ||
+			usedW*usedH == bestW*bestH && absInt(usedW-usedH) < absInt(bestW-bestH)
+		if better {
+			bestPos, bestW, bestH = positions, usedW, usedH
+		}
+	}
+	return bestPos, bestW, bestH
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+const defaultMergeQuality = 85
+
+var pageSizes = map[string][2]float64{
+	"a4":     {595.28, 841.89},
+	"letter": {612, 792},
+}
+
+type MergeOptions struct {
+	Format    string
+	PageSize  string
+	Margin    float64
+	DPI       int
+	Quality   int
+	Overwrite bool
+	Conflict  string
+}
+
+type pageLayout struct {
+	Width  float64
+	Height float64
+	X      float64
+	Y      float64
+	DrawW  float64
+	DrawH  float64
+}
+
+// Merge 将多张图像合并为多页 PDF 或 TIFF。
+func Merge(inputs []string, outputArg string, opts MergeOptions) (string, error) {
+	if len(inputs) == 0 {
+		return "", apperror.InvalidArgument("至少需要一张输入图像", nil)
+	}
+	pageSize := strings.ToLower(strings.TrimSpace(opts.PageSize))
+	if pageSize == "" {
+		pageSize = "fit"
+	}
+	if _, ok := pageSizes[pageSize]; !ok && pageSize != "fit" {
+		return "", apperror.InvalidArgument("页面尺寸仅支持 A4、letter 或 fit", nil)
+	}
+	opts.PageSize = pageSize
+	if opts.Margin < 0 {
+		return "", apperror.InvalidArgument("页边距不能为负数", nil)
+	}
+	if opts.DPI <= 0 {
+		return "", apperror.InvalidArgument("DPI 必须为正数", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputs[0],
+		OutputArg:     outputArg,
+		DesiredFormat: opts.Format,
+		InputFormat:   "pdf",
+		Conflict:      opts.Conflict,
+		Overwrite:     opts.Overwrite,
+	})
+	if err != nil {
+		return "", err
+	}
+	var data []byte
+	switch outFormat {
+	case "pdf":
+		data, err = mergePDF(inputs, opts)
+	case "tiff":
+		data, err = mergeTIFF(inputs, opts)
+	default:
+		return "", apperror.UnsupportedFormat("合并仅支持输出 PDF 与 TIFF", nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+func readMergeInput(input string) ([]byte, error) {
+	buf, err := os.ReadFile(input)
+	if err != nil {
+		return nil, apperror.InvalidInput("文件不存在或无法读取: "+input, err)
+	}
+	if DetectImageType(buf) == bimg.UNKNOWN {
+		return nil, apperror.UnsupportedFormat("无法识别输入格式: "+input, nil)
+	}
+	return buf, nil
+}
+
+func mergePDF(inputs []string, opts MergeOptions) ([]byte, error) {
+	pages := make([]pdfPage, 0, len(inputs))
+	for _, input := range inputs {
+		buf, err := readMergeInput(input)
+		if err != nil {
+			return nil, err
+		}
+		img, err := pdfJPEG(buf, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+		layout := layoutPage(img.Width, img.Height, opts)
+		pages = append(pages, pdfPage{
+			Width:  layout.Width,
+			Height: layout.Height,
+			X:      layout.X,
+			Y:      layout.Y,
+			DrawW:  layout.DrawW,
+			DrawH:  layout.DrawH,
+			Image:  img,
+		})
+	}
+	return writePDF(pages), nil
+}
+
+// pdfJPEG 在未指定质量时直接嵌入原始 JPEG，否则拍平透明度后重新压缩。
+func pdfJPEG(buf []byte, quality int) (pdfImage, error) {
+	if quality == 0 && DetectImageType(buf) == bimg.JPEG {
+		if meta, err := bimg.Metadata(buf); err == nil && meta.Orientation <= 1 {
+			if img, ok := jpegImage(buf); ok {
+				return img, nil
+			}
+		}
+	}
+	if quality == 0 {
+		quality = defaultMergeQuality
+	}
+	raster, err := decodeRaster(buf)
+	if err != nil {
+		return pdfImage{}, err
+	}
+	data, err := encodeRaster(flattenWhite(raster), bimg.JPEG, quality)
+	if err != nil {
+		return pdfImage{}, err
+	}
+	img, ok := jpegImage(data)
+	if !ok {
+		return pdfImage{}, apperror.InvalidInput("JPEG 编码失败", nil)
+	}
+	return img, nil
+}
+
+func jpegImage(data []byte) (pdfImage, bool) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return pdfImage{}, false
+	}
+	space := "DeviceRGB"
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		space = "DeviceGray"
+	case color.CMYKModel:
+		space = "DeviceCMYK"
+	}
+	return pdfImage{Data: data, Width: cfg.Width, Height: cfg.Height, ColorSpace: space}, true
+}
+
+func mergeTIFF(inputs []string, opts MergeOptions) ([]byte, error) {
+	pages := make([]*image.NRGBA, 0, len(inputs))
+	for _, input := range inputs {
+		buf, err := readMergeInput(input)
+		if err != nil {
+			return nil, err
+		}
+		img, err := decodeRaster(buf)
+		if err != nil {
+			return nil, err
+		}
+		if opts.PageSize != "fit" || opts.Margin > 0 {
+			img = placeOnPage(img, layoutPage(img.Rect.Dx(), img.Rect.Dy(), opts), opts.DPI)
+		}
+		pages = append(pages, img)
+	}
+	data, err := writeMultiPageTIFF(pages, opts.DPI)
+	if err != nil {
+		return nil, apperror.ConfigError("TIFF 编码失败", err)
+	}
+	return data, nil
+}
+
+// layoutPage 计算页面尺寸与图像位置（单位 pt），图像按 DPI 换算原始尺寸，只缩小不放大。
+func layoutPage(width, height int, opts MergeOptions) pageLayout {
+	margin := opts.Margin * 72 / 25.4
+	natW := float64(width) * 72 / float64(opts.DPI)
+	natH := float64(height) * 72 / float64(opts.DPI)
+	size, ok := pageSizes[opts.PageSize]
+	if !ok {
+		return pageLayout{
+			Width:  natW + margin*2,
+			Height: natH + margin*2,
+			X:      margin,
+			Y:      margin,
+			DrawW:  natW,
+			DrawH:  natH,
+		}
+	}
+	pageW, pageH := size[0], size[1]
+	if width > height {
+		pageW, pageH = pageH, pageW
+	}
+	boxW := math.Max(pageW-margin*2, 1)
+	boxH := math.Max(pageH-margin*2, 1)
+	scale := math.Min(1, math.Min(boxW/natW, boxH/natH))
+	drawW := natW * scale
+	drawH := natH * scale
+	return pageLayout{
+		Width:  pageW,
+		Height: pageH,
+		X:      (pageW - drawW) / 2,
+		Y:      (pageH - drawH) / 2,
+		DrawW:  drawW,
+		DrawH:  drawH,
+	}
+}
+
+func placeOnPage(img *image.NRGBA, layout pageLayout, dpi int) *image.NRGBA {
+	toPixels := func(value float64) int {
+		return int(math.Round(value * float64(dpi) / 72))
+	}
+	page := image.NewNRGBA(image.Rect(0, 0, maxInt(1, toPixels(layout.Width)), maxInt(1, toPixels(layout.Height))))
+	draw.Draw(page, page.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	drawW := maxInt(1, toPixels(layout.DrawW))
+	drawH := maxInt(1, toPixels(layout.DrawH))
+	scaled := scaleNRGBA(img, drawW, drawH)
+	left := toPixels(layout.X)
+	top := page.Rect.Dy() - toPixels(layout.Y) - drawH
+	draw.Draw(page, image.Rect(left, top, left+drawW, top+drawH), scaled, image.Point{}, draw.Over)
+	return page
+}
+
+func flattenWhite(img *image.NRGBA) *image.NRGBA {
+	if img.Opaque() {
+		return img
+	}
+	dst := image.NewNRGBA(img.Rect)
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Rect.Min, draw.Over)
+	return dst
+}
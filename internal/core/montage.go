@@ -0,0 +1,241 @@
+package core
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+	"golang.org/x/image/font"
+)
+
+type MontageOptions struct {
+	Format     string
+	Columns    int
+	Tile       string
+	Fit        string
+	Gap        int
+	Label      string
+	FontSize   int
+	FontFile   string
+	LabelColor string
+	Background string
+	PerPage    int
+	Quality    int
+	Overwrite  bool
+	Conflict   string
+}
+
+// Montage 将多张图像排列为缩略图网格，超过 PerPage 时分页输出多张拼图。
+func Montage(inputs []string, outputArg string, opts MontageOptions) ([]string, error) {
+	if len(inputs) == 0 {
+		return nil, apperror.InvalidArgument("至少需要一张输入图像", nil)
+	}
+	tileW, tileH, err := ParseTileSize(opts.Tile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Columns <= 0 {
+		return nil, apperror.InvalidArgument("列数必须为正数", nil)
+	}
+	if opts.Gap < 0 {
+		return nil, apperror.InvalidArgument("间距不能为负数", nil)
+	}
+	if opts.PerPage < 0 {
+		return nil, apperror.InvalidArgument("每页数量不能为负数", nil)
+	}
+	if err := ValidateTemplate(opts.Label); err != nil {
+		return nil, err
+	}
+	fit := opts.Fit
+	if fit == "" {
+		fit = "contain"
+	}
+	fitOptions := bimg.Options{}
+	if err := applyFit(&fitOptions, fit); err != nil {
+		return nil, err
+	}
+	background := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	switch strings.ToLower(strings.TrimSpace(opts.Background)) {
+	case "":
+	case "none", "transparent":
+		background = color.NRGBA{}
+	default:
+		parsed, ok := parseColor(opts.Background)
+		if !ok {
+			return nil, apperror.InvalidArgument("背景颜色无效", nil)
+		}
+		background = parsed
+	}
+	labelColor := color.NRGBA{R: 51, G: 51, B: 51, A: 255}
+	if opts.LabelColor != "" {
+		parsed, ok := parseColor(opts.LabelColor)
+		if !ok {
+			return nil, apperror.InvalidArgument("标签颜色无效", nil)
+		}
+		labelColor = parsed
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = 14
+	}
+	var face font.Face
+	labelH := 0
+	if opts.Label != "" {
+		fontData, err := loadFontData(opts.FontFile, "")
+		if err != nil {
+			return nil, err
+		}
+		face, err = newFontFace(fontData, opts.FontSize)
+		if err != nil {
+			return nil, err
+		}
+		defer face.Close()
+		labelH = int(math.Ceil(float64(opts.FontSize) * 1.6))
+	}
+
+	perPage := opts.PerPage
+	if perPage == 0 {
+		perPage = len(inputs)
+	}
+	sheets := (len(inputs) + perPage - 1) / perPage
+	outputs := make([]string, 0, sheets)
+	for sheet := 0; sheet < sheets; sheet++ {
+		start := sheet * perPage
+		end := minInt(start+perPage, len(inputs))
+		spec := OutputSpec{
+			InputPath:     "montage.jpg",
+			OutputArg:     outputArg,
+			DesiredFormat: opts.Format,
+			InputFormat:   "jpg",
+			Conflict:      opts.Conflict,
+			Overwrite:     opts.Overwrite,
+		}
+		if sheets > 1 {
+			suffix := fmt.Sprintf("_p%d", sheet+1)
+			if isDir, _ := outputIsDir(outputArg); isDir {
+				spec.InputPath = "montage" + suffix + ".jpg"
+			} else {
+				ext := filepath.Ext(outputArg)
+				spec.OutputArg = strings.TrimSuffix(outputArg, ext) + suffix + ext
+			}
+		}
+		outPath, outFormat, err := ResolveOutput(spec)
+		if err != nil {
+			return outputs, err
+		}
+		outType, err := ImageTypeFromFormat(outFormat)
+		if err != nil {
+			return outputs, err
+		}
+		if !IsTypeSupportedSave(outType) {
+			return outputs, apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+		}
+
+		count := end - start
+		columns := minInt(opts.Columns, count)
+		rows := (count + columns - 1) / columns
+		cellH := tileH + labelH
+		canvas := image.NewNRGBA(image.Rect(0, 0, columns*tileW+(columns+1)*opts.Gap, rows*cellH+(rows+1)*opts.Gap))
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+		transform := resizeFrameTransform(tileW, tileH, fitOptions)
+		for i, input := range inputs[start:end] {
+			buf, err := os.ReadFile(input)
+			if err != nil {
+				return outputs, apperror.InvalidInput("文件不存在或无法读取: "+input, err)
+			}
+			if DetectImageType(buf) == bimg.UNKNOWN {
+				return outputs, apperror.UnsupportedFormat("无法识别输入格式: "+input, nil)
+			}
+			img, err := decodeRaster(buf)
+			if err != nil {
+				return outputs, err
+			}
+			thumb, err := transform(img)
+			if err != nil {
+				return outputs, err
+			}
+			col := i % columns
+			row := i / columns
+			cellX := opts.Gap + col*(tileW+opts.Gap)
+			cellY := opts.Gap + row*(cellH+opts.Gap)
+			left := cellX + (tileW-thumb.Rect.Dx())/2
+			top := cellY + (tileH-thumb.Rect.Dy())/2
+			draw.Draw(canvas, image.Rect(left, top, left+thumb.Rect.Dx(), top+thumb.Rect.Dy()), thumb, image.Point{}, draw.Over)
+			if face == nil {
+				continue
+			}
+			label := opts.Label
+			if HasTemplate(label) {
+				ctx, err := NewTemplateContext(input, start+i+1)
+				if err != nil {
+					return outputs, err
+				}
+				label, err = ExpandTemplate(label, ctx)
+				if err != nil {
+					return outputs, err
+				}
+			}
+			drawLabel(canvas, face, label, cellX, cellY+tileH, tileW, labelH, labelColor)
+		}
+		var result image.Image = canvas
+		if outType == bimg.JPEG {
+			result = flattenWhite(canvas)
+		}
+		data, err := encodeRaster(result, outType, opts.Quality)
+		if err != nil {
+			return outputs, err
+		}
+		if err := os.WriteFile(outPath, data, 0o644); err != nil {
+			return outputs, apperror.ConfigError("无法写入输出文件", err)
+		}
+		outputs = append(outputs, outPath)
+	}
+	return outputs, nil
+}
+
+// ParseTileSize 解析 "300x300" 形式的尺寸，单个数字表示正方形。
+func ParseTileSize(value string) (int, int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, 0, apperror.InvalidArgument("尺寸不能为空", nil)
+	}
+	widthValue, heightValue, found := strings.Cut(value, "x")
+	if !found {
+		heightValue = widthValue
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(widthValue))
+	if err != nil || width <= 0 {
+		return 0, 0, apperror.InvalidArgument("尺寸无效: "+value, err)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(heightValue))
+	if err != nil || height <= 0 {
+		return 0, 0, apperror.InvalidArgument("尺寸无效: "+value, err)
+	}
+	return width, height, nil
+}
+
+// drawLabel 在单元格下方居中绘制标签，超出宽度时截断并补省略号。
+func drawLabel(canvas *image.NRGBA, face font.Face, label string, x, y, width, height int, c color.NRGBA) {
+	if label == "" {
+		return
+	}
+	runes := []rune(label)
+	text := label
+	for font.MeasureString(face, text).Ceil() > width && len(runes) > 1 {
+		runes = runes[:len(runes)-1]
+		text = string(runes) + "…"
+	}
+	textW := font.MeasureString(face, text).Ceil()
+	metrics := face.Metrics()
+	textH := (metrics.Ascent + metrics.Descent).Ceil()
+	cell := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawText(cell, face, maxInt(0, (width-textW)/2), (height-textH)/2+metrics.Ascent.Ceil(), text, c)
+	draw.Draw(canvas, image.Rect(x, y, x+width, y+height), cell, image.Point{}, draw.Over)
+}
@@ -28,6 +28,10 @@ func ResolveOutput(spec OutputSpec) (string, string, error) {
 	}
 	format := NormalizeFormat(spec.DesiredFormat)
 	inputFormat := NormalizeFormat(spec.InputFormat)
+	if inputFormat == "raw" {
+		// RAW 仅支持输入，未指定格式时输出 JPEG。
+		inputFormat = "jpg"
+	}
 	if isDir {
 		if format == "" {
 			format = inputFormat
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+var pdfPagePattern = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+// isPagedFormat 判断格式是否可能包含多页。
+func isPagedFormat(format string) bool {
+	format = NormalizeFormat(format)
+	return format == "pdf" || format == "tiff"
+}
+
+// PageCount 返回 PDF/TIFF 的页数，非多页格式返回 false。
+func PageCount(inputPath string, buf []byte) (int, bool) {
+	format := FormatFromImageType(DetectImageType(buf))
+	if !isPagedFormat(format) {
+		return 0, false
+	}
+	if format == "tiff" {
+		if count := tiffPageCount(buf); count > 0 {
+			return count, true
+		}
+	}
+	if cmdPath, ok := VipsHeaderCommand(); ok {
+		output, err := exec.Command(cmdPath, "-f", "n-pages", inputPath).Output()
+		if err == nil {
+			if count, err := strconv.Atoi(strings.TrimSpace(string(output))); err == nil && count > 0 {
+				return count, true
+			}
+		}
+	}
+	if format == "pdf" {
+		if count := len(pdfPagePattern.FindAll(buf, -1)); count > 0 {
+			return count, true
+		}
+	}
+	return 1, true
+}
+
+// tiffPageCount 沿 IFD 链统计 TIFF 页数，无法解析时返回 0。
+func tiffPageCount(buf []byte) int {
+	if len(buf) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(buf[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(buf[2:4]) != 42 {
+		return 0
+	}
+	offset := int64(order.Uint32(buf[4:8]))
+	seen := map[int64]struct{}{}
+	count := 0
+	for offset != 0 {
+		if offset+2 > int64(len(buf)) {
+			break
+		}
+		if _, ok := seen[offset]; ok {
+			break
+		}
+		seen[offset] = struct{}{}
+		entries := int64(order.Uint16(buf[offset : offset+2]))
+		next := offset + 2 + entries*12
+		if next+4 > int64(len(buf)) {
+			count++
+			break
+		}
+		count++
+		offset = int64(order.Uint32(buf[next : next+4]))
+	}
+	return count
+}
+
+// ParsePageRange 解析 "1-5,8" 形式的页码列表（从 1 开始），空值或 all 表示全部页。
+func ParsePageRange(value string, total int) ([]int, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" || value == "all" {
+		pages := make([]int, 0, total)
+		for i := 1; i <= total; i++ {
+			pages = append(pages, i)
+		}
+		return pages, nil
+	}
+	seen := map[int]struct{}{}
+	pages := []int{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		start, end := part, part
+		if idx := strings.Index(part, "-"); idx >= 0 {
+			start = strings.TrimSpace(part[:idx])
+			end = strings.TrimSpace(part[idx+1:])
+		}
+		from, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, apperror.InvalidArgument("页码无效: "+part, err)
+		}
+		to := total
+		if end != "" {
+			to, err = strconv.Atoi(end)
+			if err != nil {
+				return nil, apperror.InvalidArgument("页码无效: "+part, err)
+			}
+		}
+		if from < 1 || to < from {
+			return nil, apperror.InvalidArgument("页码范围无效: "+part, nil)
+		}
+		if to > total {
+			return nil, apperror.InvalidArgument(fmt.Sprintf("页码超出范围: %s (共 %d 页)", part, total), nil)
+		}
+		for page := from; page <= to; page++ {
+			if _, ok := seen[page]; ok {
+				continue
+			}
+			seen[page] = struct{}{}
+			pages = append(pages, page)
+		}
+	}
+	if len(pages) == 0 {
+		return nil, apperror.InvalidArgument("页码列表为空", nil)
+	}
+	sort.Ints(pages)
+	return pages, nil
+}
+
+// ConvertPages 将 PDF/多页 TIFF 的指定页分别输出为 {stem}_p{page} 图像。
+func ConvertPages(inputPath, outputArg string, opts ConvertOptions) ([]string, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return nil, err
+	}
+	inputFormat := FormatFromImageType(inputType)
+	if !isPagedFormat(inputFormat) {
+		return nil, apperror.InvalidArgument("仅 PDF 与 TIFF 支持按页转换", nil)
+	}
+	if opts.DPI < 0 {
+		return nil, apperror.InvalidArgument("DPI 不能为负数", nil)
+	}
+	if opts.DPI > 0 && inputFormat != "pdf" {
+		return nil, apperror.InvalidArgument("--dpi 仅适用于 PDF 输入", nil)
+	}
+	total, _ := PageCount(inputPath, buf)
+	pages, err := ParsePageRange(opts.Pages, total)
+	if err != nil {
+		return nil, err
+	}
+	isDir, err := outputIsDir(outputArg)
+	if err != nil {
+		return nil, apperror.ConfigError("无法读取输出路径", err)
+	}
+	defaultFormat := inputFormat
+	if defaultFormat == "pdf" {
+		defaultFormat = "png"
+	}
+	base := filepath.Base(inputPath)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	outputs := make([]string, 0, len(pages))
+	for _, page := range pages {
+		suffix := fmt.Sprintf("_p%d", page)
+		spec := OutputSpec{
+			InputPath:     filepath.Join(filepath.Dir(inputPath), stem+suffix+filepath.Ext(base)),
+			OutputArg:     outputArg,
+			DesiredFormat: opts.Format,
+			InputFormat:   defaultFormat,
+			Conflict:      opts.Conflict,
+			Overwrite:     opts.Overwrite,
+		}
+		if !isDir {
+			ext := filepath.Ext(outputArg)
+			spec.OutputArg = strings.TrimSuffix(outputArg, ext) + suffix + ext
+		}
+		outPath, outFormat, err := ResolveOutput(spec)
+		if err != nil {
+			return outputs, err
+		}
+		outType, err := ImageTypeFromFormat(outFormat)
+		if err != nil {
+			return outputs, err
+		}
+		if !IsTypeSupportedSave(outType) {
+			return outputs, apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+		}
+		pageBuf, err := rasterizePage(inputPath, inputFormat, page-1, opts.DPI)
+		if err != nil {
+			return outputs, err
+		}
+		newImage := pageBuf
+		if outType != bimg.PNG {
+			newImage, err = processWithQuality(pageBuf, outType, opts.Quality)
+			if err != nil {
+				return outputs, err
+			}
+		}
+		if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+			return outputs, apperror.ConfigError("无法写入输出文件", err)
+		}
+		outputs = append(outputs, outPath)
+	}
+	return outputs, nil
+}
+
+// rasterizePage 借助 vips 或 ImageMagick 将指定页（从 0 开始）渲染为 PNG。
+func rasterizePage(inputPath, format string, page, dpi int) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "image-cli-page-")
+	if err != nil {
+		return nil, apperror.ConfigError("无法创建临时目录", err)
+	}
+	defer os.RemoveAll(tempDir)
+	outPath := filepath.Join(tempDir, "page.png")
+	var cmd *exec.Cmd
+	if cmdPath, ok := VipsCommand(); ok {
+		loadOpts := fmt.Sprintf("page=%d", page)
+		if dpi > 0 && format == "pdf" {
+			loadOpts += fmt.Sprintf(",dpi=%d", dpi)
+		}
+		cmd = exec.Command(cmdPath, "copy", fmt.Sprintf("%s[%s]", inputPath, loadOpts), outPath)
+	} else if cmdPath, ok := ImageMagickCommand(); ok {
+		args := []string{}
+		if dpi > 0 && format == "pdf" {
+			args = append(args, "-density", strconv.Itoa(dpi))
+		}
+		args = append(args, fmt.Sprintf("%s[%d]", inputPath, page), outPath)
+		cmd = exec.Command(cmdPath, args...)
+	} else {
+		return nil, apperror.UnsupportedFormat("按页转换需要安装 vips 命令行工具或 ImageMagick", nil)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, apperror.InvalidInput(fmt.Sprintf("第 %d 页渲染失败: %s", page+1, strings.TrimSpace(string(output))), err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, apperror.ConfigError("无法读取临时文件", err)
+	}
+	return data, nil
+}
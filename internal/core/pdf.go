@@ -0,0 +1,86 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type pdfImage struct {
+	Data       []byte
+	Width      int
+	Height     int
+	ColorSpace string
+}
+
+type pdfPage struct {
+	Width  float64
+	Height float64
+	X      float64
+	Y      float64
+	DrawW  float64
+	DrawH  float64
+	Image  pdfImage
+}
+
+// writePDF 生成每页嵌入一张 JPEG (DCTDecode) 的 PDF 文档，坐标单位为 pt。
+func writePDF(pages []pdfPage) []byte {
+	var buf bytes.Buffer
+	offsets := []int{0}
+	beginObject := func() int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets) - 1
+		fmt.Fprintf(&buf, "%d 0 obj\n", id)
+		return id
+	}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	beginObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+i*3)
+	}
+	beginObject()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Count %d /Kids [", len(pages))
+	for i, kid := range kids {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(kid)
+	}
+	buf.WriteString("] >>\nendobj\n")
+
+	for _, page := range pages {
+		pageID := beginObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pdfNumber(page.Width), pdfNumber(page.Height), pageID+2, pageID+1)
+
+		content := fmt.Sprintf("q %s 0 0 %s %s %s cm /Im0 Do Q\n",
+			pdfNumber(page.DrawW), pdfNumber(page.DrawH), pdfNumber(page.X), pdfNumber(page.Y))
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+
+		beginObject()
+		decode := ""
+		if page.Image.ColorSpace == "DeviceCMYK" {
+			decode = " /Decode [1 0 1 0 1 0 1 0]"
+		}
+		fmt.Fprintf(&buf, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode%s /Length %d >>\nstream\n",
+			page.Image.Width, page.Image.Height, page.Image.ColorSpace, decode, len(page.Image.Data))
+		buf.Write(page.Image.Data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+	return buf.Bytes()
+}
+
+func pdfNumber(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"image"
+	"os"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// PipelineStep 为流水线中的一个处理步骤，Adjust 与 Filter 必须且只能设置其一。
+type PipelineStep struct {
+	Adjust *AdjustOptions
+	Filter *FilterOptions
+}
+
+// PipelineOptions 中 Steps 按顺序作用于同一幅图像，仅在最后编码一次。
+type PipelineOptions struct {
+	Steps    []PipelineStep
+	Quality  int
+	Conflict string
+}
+
+// Pipeline 依次执行多个调整与滤镜步骤，避免逐条命令处理时的多次有损编码；动图逐帧处理。
+func Pipeline(inputPath, outputArg string, opts PipelineOptions) (string, error) {
+	if len(opts.Steps) == 0 {
+		return "", apperror.InvalidArgument("流水线至少需要一个步骤", nil)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return "", apperror.InvalidArgument("质量必须在 1-100 之间", nil)
+	}
+	transforms := make([]frameTransform, 0, len(opts.Steps))
+	for _, step := range opts.Steps {
+		switch {
+		case step.Adjust != nil && step.Filter == nil:
+			transform, err := AdjustTransform(*step.Adjust)
+			if err != nil {
+				return "", err
+			}
+			transforms = append(transforms, transform)
+		case step.Filter != nil && step.Adjust == nil:
+			if err := validateFilterOptions(*step.Filter); err != nil {
+				return "", err
+			}
+			transforms = append(transforms, FilterTransform(*step.Filter))
+		default:
+			return "", apperror.InvalidArgument("流水线步骤必须为 adjust 或 filter 之一", nil)
+		}
+	}
+	transform := func(frame *image.NRGBA) (*image.NRGBA, error) {
+		var err error
+		for _, step := range transforms {
+			frame, err = step(frame)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return frame, nil
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, transform)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		img, err := decodeRaster(buf)
+		if err != nil {
+			return "", err
+		}
+		img, err = transform(img)
+		if err != nil {
+			return "", err
+		}
+		newImage, err = encodeRaster(img, outType, opts.Quality)
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
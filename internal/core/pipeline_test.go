@@ -0,0 +1,68 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+func TestPipelineAppliesStepsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 64, 48)
+	adjust := AdjustOptions{Brightness: 1.2, Contrast: 1.3, Saturation: 0.5, Gamma: 1}
+	filter := FilterOptions{Negate: true, Pixelate: 4}
+	outPath, err := Pipeline(input, filepath.Join(dir, "out.png"), PipelineOptions{
+		Steps: []PipelineStep{{Adjust: &adjust}, {Filter: &filter}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adjustTransform, err := AdjustTransform(adjust)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img, err = adjustTransform(img); err != nil {
+		t.Fatal(err)
+	}
+	if img, err = FilterTransform(filter)(img); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeRaster(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Pix, img.Pix) {
+		t.Error("pipeline output differs from applying adjust then filter")
+	}
+}
+
+func TestPipelineRejectsInvalidSteps(t *testing.T) {
+	dir := t.TempDir()
+	input := writeGradientPNG(t, dir, 8, 8)
+	for _, steps := range [][]PipelineStep{
+		nil,
+		{{}},
+		{{Adjust: &AdjustOptions{Brightness: 1, Contrast: 1, Saturation: 1, Gamma: 1}, Filter: &FilterOptions{Negate: true}}},
+		{{Filter: &FilterOptions{}}},
+	} {
+		_, err := Pipeline(input, filepath.Join(dir, "out.png"), PipelineOptions{Steps: steps})
+		if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != "E007" {
+			t.Errorf("steps %+v: err = %v, want E007", steps, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+)
+
+// QOI 编解码，参见 https://qoiformat.org/qoi-specification.pdf
+const (
+	qoiOpIndex = 0x00
+	qoiOpDiff  = 0x40
+	qoiOpLuma  = 0x80
+	qoiOpRun   = 0xc0
+	qoiOpRGB   = 0xfe
+	qoiOpRGBA  = 0xff
+	qoiMask2   = 0xc0
+	qoiMaxPix  = 400000000
+)
+
+var qoiPadding = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+
+func isQOI(buf []byte) bool {
+	return len(buf) >= 14 && string(buf[:4]) == "qoif"
+}
+
+func qoiHash(r, g, b, a byte) int {
+	return (int(r)*3 + int(g)*5 + int(b)*7 + int(a)*11) % 64
+}
+
+func encodeQOI(img *image.NRGBA) []byte {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	channels := byte(4)
+	if img.Opaque() {
+		channels = 3
+	}
+	var out bytes.Buffer
+	out.Grow(14 + width*height + len(qoiPadding))
+	out.WriteString("qoif")
+	binary.Write(&out, binary.BigEndian, uint32(width))
+	binary.Write(&out, binary.BigEndian, uint32(height))
+	out.WriteByte(channels)
+	out.WriteByte(0)
+
+	var index [64][4]byte
+	prev := [4]byte{0, 0, 0, 255}
+	run := 0
+	total := width * height
+	for i := 0; i < total; i++ {
+		offset := (i/width)*img.Stride + (i%width)*4
+		px := [4]byte{img.Pix[offset], img.Pix[offset+1], img.Pix[offset+2], img.Pix[offset+3]}
+		if px == prev {
+			run++
+			if run == 62 || i == total-1 {
+				out.WriteByte(qoiOpRun | byte(run-1))
+				run = 0
+			}
+			continue
+		}
+		if run > 0 {
+			out.WriteByte(qoiOpRun | byte(run-1))
+			run = 0
+		}
+		hash := qoiHash(px[0], px[1], px[2], px[3])
+		if index[hash] == px {
+			out.WriteByte(qoiOpIndex | byte(hash))
+			prev = px
+			continue
+		}
+		index[hash] = px
+		if px[3] != prev[3] {
+			out.WriteByte(qoiOpRGBA)
+			out.Write(px[:])
+			prev = px
+			continue
+		}
+		vr := int8(px[0] - prev[0])
+		vg := int8(px[1] - prev[1])
+		vb := int8(px[2] - prev[2])
+		vgr := vr - vg
+		vgb := vb - vg
+		switch {
+		case vr > -3 && vr < 2 && vg > -3 && vg < 2 && vb > -3 && vb < 2:
+			out.WriteByte(qoiOpDiff | byte(vr+2)<<4 | byte(vg+2)<<2 | byte(vb+2))
+		case vgr > -9 && vgr < 8 && vg > -33 && vg < 32 && vgb > -9 && vgb < 8:
+			out.WriteByte(qoiOpLuma | byte(vg+32))
+			out.WriteByte(byte(vgr+8)<<4 | byte(vgb+8))
+		default:
+			out.WriteByte(qoiOpRGB)
+			out.Write(px[:3])
+		}
+		prev = px
+	}
+	out.Write(qoiPadding)
+	return out.Bytes()
+}
+
+func decodeQOI(buf []byte) (*image.NRGBA, error) {
+	if !isQOI(buf) {
+		return nil, errors.New("invalid qoi header")
+	}
+	width := int(binary.BigEndian.Uint32(buf[4:8]))
+	height := int(binary.BigEndian.Uint32(buf[8:12]))
+	if width == 0 || height == 0 || height > qoiMaxPix/width {
+		return nil, errors.New("invalid qoi dimensions")
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	var index [64][4]byte
+	px := [4]byte{0, 0, 0, 255}
+	pos := 14
+	end := len(buf) - len(qoiPadding)
+	run := 0
+	for offset := 0; offset < len(img.Pix); offset += 4 {
+		if run > 0 {
+			run--
+		} else if pos < end {
+			op := buf[pos]
+			pos++
+			switch {
+			case op == qoiOpRGB:
+				if pos+3 > end {
+					return nil, errors.New("truncated qoi data")
+				}
+				copy(px[:3], buf[pos:pos+3])
+				pos += 3
+			case op == qoiOpRGBA:
+				if pos+4 > end {
+					return nil, errors.New("truncated qoi data")
+				}
+				copy(px[:], buf[pos:pos+4])
+				pos += 4
+			case op&qoiMask2 == qoiOpIndex:
+				px = index[op]
+			case op&qoiMask2 == qoiOpDiff:
+				px[0] += (op>>4)&0x03 - 2
+				px[1] += (op>>2)&0x03 - 2
+				px[2] += op&0x03 - 2
+			case op&qoiMask2 == qoiOpLuma:
+				if pos >= end {
+					return nil, errors.New("truncated qoi data")
+				}
+				next := buf[pos]
+				pos++
+				vg := op&0x3f - 32
+				px[0] += vg - 8 + (next>>4)&0x0f
+				px[1] += vg
+				px[2] += vg - 8 + next&0x0f
+			default:
+				run = int(op & 0x3f)
+			}
+			index[qoiHash(px[0], px[1], px[2], px[3])] = px
+		}
+		copy(img.Pix[offset:offset+4], px[:])
+	}
+	return img, nil
+}
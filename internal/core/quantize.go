@@ -0,0 +1,150 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+type colorBox struct {
+	colors []color.NRGBA
+}
+
+func (b colorBox) longestAxis() (int, int) {
+	minC := [3]int{255, 255, 255}
+	maxC := [3]int{}
+	for _, c := range b.colors {
+		values := [3]int{int(c.R), int(c.G), int(c.B)}
+		for i, v := range values {
+			if v < minC[i] {
+				minC[i] = v
+			}
+			if v > maxC[i] {
+				maxC[i] = v
+			}
+		}
+	}
+	axis := 0
+	span := -1
+	for i := 0; i < 3; i++ {
+		if maxC[i]-minC[i] > span {
+			axis = i
+			span = maxC[i] - minC[i]
+		}
+	}
+	return axis, span
+}
+
+func (b colorBox) average() color.NRGBA {
+	var r, g, bl int
+	for _, c := range b.colors {
+		r += int(c.R)
+		g += int(c.G)
+		bl += int(c.B)
+	}
+	n := len(b.colors)
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 255}
+}
+
+func channel(c color.NRGBA, axis int) uint8 {
+	switch axis {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// medianCutPalette 用中位切分从若干帧中生成共享调色板，maxColors 不含透明色。
+func medianCutPalette(frames []*image.NRGBA, maxColors int) color.Palette {
+	samples := make([]color.NRGBA, 0, 4096)
+	for _, frame := range frames {
+		pixels := len(frame.Pix) / 4
+		step := pixels/(65536/len(frames)+1) + 1
+		for i := 0; i < pixels; i += step {
+			offset := i * 4
+			if frame.Pix[offset+3] < 128 {
+				continue
+			}
+			samples = append(samples, color.NRGBA{R: frame.Pix[offset], G: frame.Pix[offset+1], B: frame.Pix[offset+2], A: 255})
+		}
+	}
+	if len(samples) == 0 {
+		return color.Palette{color.NRGBA{A: 255}}
+	}
+	boxes := []colorBox{{colors: samples}}
+	for len(boxes) < maxColors {
+		target := -1
+		targetSpan := 0
+		for i, box := range boxes {
+			if len(box.colors) < 2 {
+				continue
+			}
+			if _, span := box.longestAxis(); span > targetSpan {
+				target = i
+				targetSpan = span
+			}
+		}
+		if target < 0 {
+			break
+		}
+		box := boxes[target]
+		axis, _ := box.longestAxis()
+		sort.Slice(box.colors, func(i, j int) bool {
+			return channel(box.colors[i], axis) < channel(box.colors[j], axis)
+		})
+		mid := len(box.colors) / 2
+		boxes[target] = colorBox{colors: box.colors[:mid]}
+		boxes = append(boxes, colorBox{colors: box.colors[mid:]})
+	}
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		palette = append(palette, box.average())
+	}
+	return palette
+}
+
+func toPaletted(img *image.NRGBA, palette color.Palette, transparentIndex int) *image.Paletted {
+	bounds := img.Rect
+	dst := image.NewPaletted(bounds, palette)
+	cache := make(map[uint32]uint8, 1024)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			offset := y*img.Stride + x*4
+			if img.Pix[offset+3] < 128 && transparentIndex >= 0 {
+				dst.Pix[y*dst.Stride+x] = uint8(transparentIndex)
+				continue
+			}
+			key := uint32(img.Pix[offset])<<16 | uint32(img.Pix[offset+1])<<8 | uint32(img.Pix[offset+2])
+			index, ok := cache[key]
+			if !ok {
+				index = uint8(nearestOpaque(palette, img.Pix[offset], img.Pix[offset+1], img.Pix[offset+2], transparentIndex))
+				cache[key] = index
+			}
+			dst.Pix[y*dst.Stride+x] = index
+		}
+	}
+	return dst
+}
+
+func nearestOpaque(palette color.Palette, r, g, b uint8, skip int) int {
+	best := 0
+	bestDist := -1
+	for i, c := range palette {
+		if i == skip {
+			continue
+		}
+		pr, pg, pb, _ := c.RGBA()
+		dr := int(pr>>8) - int(r)
+		dg := int(pg>>8) - int(g)
+		db := int(pb>>8) - int(b)
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best = i
+			bestDist = dist
+		}
+	}
+	return best
+}
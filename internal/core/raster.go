@@ -0,0 +1,56 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+func decodeRaster(buf []byte) (*image.NRGBA, error) {
+	buf, _, err := NormalizeImage(buf)
+	if err != nil {
+		return nil, err
+	}
+	pngBuf, err := bimg.NewImage(buf).Process(bimg.Options{Type: bimg.PNG})
+	if err != nil {
+		return nil, apperror.InvalidInput("图像处理失败", err)
+	}
+	img, err := png.Decode(bytes.NewReader(pngBuf))
+	if err != nil {
+		return nil, apperror.InvalidInput("无法解析图像", err)
+	}
+	return toNRGBA(img), nil
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, apperror.ConfigError("PNG 编码失败", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeRaster(img image.Image, outType bimg.ImageType, quality int) ([]byte, error) {
+	pngBuf, err := encodePNG(img)
+	if err != nil {
+		return nil, err
+	}
+	if outType == bimg.PNG {
+		return pngBuf, nil
+	}
+	return processWithQuality(pngBuf, outType, quality)
+}
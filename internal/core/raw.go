@@ -0,0 +1,384 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// RawOptions 为 RAW 输入的基础冲印参数，对其它格式无效。
+type RawOptions struct {
+	WhiteBalance string
+	Exposure     float64
+}
+
+const (
+	tiffTagCompression    = 0x0103
+	tiffTagPhotometric    = 0x0106
+	tiffTagMake           = 0x010f
+	tiffTagStripOffsets   = 0x0111
+	tiffTagOrientation    = 0x0112
+	tiffTagStripByteCount = 0x0117
+	tiffTagSubIFDs        = 0x014a
+	tiffTagJPEGOffset     = 0x0201
+	tiffTagJPEGLength     = 0x0202
+	tiffTagDNGVersion     = 0xc612
+	tiffTagDNGPrivateData = 0xc634
+
+	// 相机厂商私有的 RAW 压缩方式与 CFA（拜耳阵列）光度解释。
+	tiffCompressionNEF = 34713
+	tiffCompressionARW = 32767
+	tiffPhotometricCFA = 32803
+)
+
+type tiffField struct {
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+type tiffIFD map[uint16]tiffField
+
+func validateRawOptions(opts RawOptions) error {
+	switch strings.ToLower(opts.WhiteBalance) {
+	case "", "camera", "auto":
+	default:
+		return apperror.InvalidArgument("白平衡仅支持 camera 或 auto", nil)
+	}
+	if opts.Exposure < -5 || opts.Exposure > 5 {
+		return apperror.InvalidArgument("曝光补偿必须在 -5 到 5 EV 之间", nil)
+	}
+	return nil
+}
+
+// rawKind 识别基于 TIFF 结构的相机 RAW 文件，返回 dng、cr2、nef、arw 或空字符串。
+func rawKind(buf []byte) string {
+	order, ok := tiffByteOrder(buf)
+	if !ok {
+		return ""
+	}
+	if len(buf) >= 10 && buf[8] == 'C' && buf[9] == 'R' {
+		return "cr2"
+	}
+	ifd, _, ok := readTIFFIFD(buf, order, order.Uint32(buf[4:8]))
+	if !ok {
+		return ""
+	}
+	if _, ok := ifd[tiffTagDNGVersion]; ok {
+		return "dng"
+	}
+	// 厂商名只用于区分 NEF 与 ARW，还须在 IFD0 或 SubIFD 中找到传感器原始数据，
+	// 否则相机或扫描软件导出的普通 TIFF 也会被当作 RAW。
+	maker := strings.ToUpper(ifd.str(tiffTagMake))
+	switch {
+	case strings.HasPrefix(maker, "NIKON"):
+		if hasRawSensorData(buf, order, ifd, tiffCompressionNEF) {
+			return "nef"
+		}
+	case strings.HasPrefix(maker, "SONY"):
+		// ARW 的 IFD0 带有指向 SR2 私有数据的 DNGPrivateData 标签。
+		if _, ok := ifd[tiffTagDNGPrivateData]; ok || hasRawSensorData(buf, order, ifd, tiffCompressionARW) {
+			return "arw"
+		}
+	}
+	return ""
+}
+
+// hasRawSensorData 检查 IFD0 及其 SubIFD 中是否存在厂商 RAW 压缩或 CFA 光度解释的图像数据。
+func hasRawSensorData(buf []byte, order binary.ByteOrder, ifd0 tiffIFD, compression uint32) bool {
+	ifds := []tiffIFD{ifd0}
+	for _, offset := range ifd0.uints(tiffTagSubIFDs, order) {
+		if sub, _, ok := readTIFFIFD(buf, order, offset); ok {
+			ifds = append(ifds, sub)
+		}
+	}
+	for _, ifd := range ifds {
+		if value, ok := ifd.uint(tiffTagCompression, order); ok && value == compression {
+			return true
+		}
+		if value, ok := ifd.uint(tiffTagPhotometric, order); ok && value == tiffPhotometricCFA {
+			return true
+		}
+	}
+	return false
+}
+
+func tiffByteOrder(buf []byte) (binary.ByteOrder, bool) {
+	if len(buf) < 8 {
+		return nil, false
+	}
+	var order binary.ByteOrder
+	switch string(buf[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, false
+	}
+	if order.Uint16(buf[2:4]) != 42 {
+		return nil, false
+	}
+	return order, true
+}
+
+func readTIFFIFD(buf []byte, order binary.ByteOrder, offset uint32) (tiffIFD, uint32, bool) {
+	start := int64(offset)
+	if offset == 0 || start+2 > int64(len(buf)) {
+		return nil, 0, false
+	}
+	count := int64(order.Uint16(buf[start : start+2]))
+	end := start + 2 + count*12
+	if end+4 > int64(len(buf)) {
+		return nil, 0, false
+	}
+	typeSizes := map[uint16]int64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4}
+	ifd := tiffIFD{}
+	for i := int64(0); i < count; i++ {
+		entry := buf[start+2+i*12 : start+14+i*12]
+		tag := order.Uint16(entry[0:2])
+		typ := order.Uint16(entry[2:4])
+		n := order.Uint32(entry[4:8])
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := size * int64(n)
+		data := entry[8:12]
+		if total > 4 {
+			pos := int64(order.Uint32(entry[8:12]))
+			if pos+total > int64(len(buf)) {
+				continue
+			}
+			data = buf[pos : pos+total]
+		}
+		ifd[tag] = tiffField{typ: typ, count: n, data: data}
+	}
+	return ifd, order.Uint32(buf[end : end+4]), true
+}
+
+func (ifd tiffIFD) uints(tag uint16, order binary.ByteOrder) []uint32 {
+	entry, ok := ifd[tag]
+	if !ok {
+		return nil
+	}
+	values := make([]uint32, 0, entry.count)
+	for i := 0; i < int(entry.count); i++ {
+		switch entry.typ {
+		case 3:
+			if (i+1)*2 <= len(entry.data) {
+				values = append(values, uint32(order.Uint16(entry.data[i*2:])))
+			}
+		case 4, 13:
+			if (i+1)*4 <= len(entry.data) {
+				values = append(values, order.Uint32(entry.data[i*4:]))
+			}
+		}
+	}
+	return values
+}
+
+func (ifd tiffIFD) uint(tag uint16, order binary.ByteOrder) (uint32, bool) {
+	values := ifd.uints(tag, order)
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+func (ifd tiffIFD) str(tag uint16) string {
+	entry, ok := ifd[tag]
+	if !ok || entry.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(entry.data), "\x00"))
+}
+
+// developRAW 解码 RAW 并应用白平衡与曝光，返回 PNG。
+// 依次尝试 vips (libraw)、ImageMagick，最后回退到纯 Go 提取内嵌 JPEG 预览。
+func developRAW(buf []byte, opts RawOptions) ([]byte, error) {
+	kind := rawKind(buf)
+	var img *image.NRGBA
+	if pngBuf, err := externalRAWDecode(buf, kind); err == nil {
+		decoded, err := decodeRaster(pngBuf)
+		if err != nil {
+			return nil, err
+		}
+		img = decoded
+	} else {
+		preview, err := rawPreview(buf)
+		if err != nil {
+			return nil, err
+		}
+		img = preview
+	}
+	if strings.EqualFold(opts.WhiteBalance, "auto") {
+		grayWorldBalance(img)
+	}
+	applyExposure(img, opts.Exposure)
+	return encodePNG(img)
+}
+
+// RAWDecoder 返回当前环境下 RAW 输入使用的解码方式。
+func RAWDecoder() string {
+	if _, ok := VipsCommand(); ok && hasVipsForeign("dcrawload") {
+		return "libvips (libraw)"
+	}
+	if HasImageMagick() {
+		return "ImageMagick，失败时使用内嵌 JPEG 预览"
+	}
+	return "内嵌 JPEG 预览 (纯 Go)"
+}
+
+func externalRAWDecode(buf []byte, kind string) ([]byte, error) {
+	var cmdPath string
+	var args func(in, out string) []string
+	if path, ok := VipsCommand(); ok && hasVipsForeign("dcrawload") {
+		cmdPath = path
+		args = func(in, out string) []string { return []string{"dcrawload", in, out} }
+	} else if path, ok := ImageMagickCommand(); ok {
+		cmdPath = path
+		args = func(in, out string) []string { return []string{kind + ":" + in, "-auto-orient", "png:" + out} }
+	} else {
+		return nil, apperror.UnsupportedFormat("未找到 RAW 解码工具", nil)
+	}
+	tempDir, err := os.MkdirTemp("", "image-cli-raw-")
+	if err != nil {
+		return nil, apperror.ConfigError("无法创建临时目录", err)
+	}
+	defer os.RemoveAll(tempDir)
+	inPath := filepath.Join(tempDir, "input."+kind)
+	outPath := filepath.Join(tempDir, "output.png")
+	if err := os.WriteFile(inPath, buf, 0o644); err != nil {
+		return nil, apperror.ConfigError("无法写入临时文件", err)
+	}
+	output, err := exec.Command(cmdPath, args(inPath, outPath)...).CombinedOutput()
+	if err != nil {
+		return nil, apperror.InvalidInput("RAW 解码失败: "+strings.TrimSpace(string(output)), err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, apperror.ConfigError("无法读取临时文件", err)
+	}
+	return data, nil
+}
+
+// rawPreview 遍历 IFD 链与 SubIFD，解码面积最大的内嵌 JPEG 预览并按 IFD0 方向校正。
+func rawPreview(buf []byte) (*image.NRGBA, error) {
+	order, ok := tiffByteOrder(buf)
+	if !ok {
+		return nil, apperror.InvalidInput("无法解析 RAW 文件", nil)
+	}
+	orientation := uint32(1)
+	queue := []uint32{order.Uint32(buf[4:8])}
+	seen := map[uint32]struct{}{}
+	var best []byte
+	bestArea := 0
+	for len(queue) > 0 && len(seen) < 64 {
+		offset := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[offset]; ok {
+			continue
+		}
+		seen[offset] = struct{}{}
+		ifd, next, ok := readTIFFIFD(buf, order, offset)
+		if !ok {
+			continue
+		}
+		if len(seen) == 1 {
+			if value, ok := ifd.uint(tiffTagOrientation, order); ok {
+				orientation = value
+			}
+		}
+		if next != 0 {
+			queue = append(queue, next)
+		}
+		queue = append(queue, ifd.uints(tiffTagSubIFDs, order)...)
+		for _, data := range ifd.jpegCandidates(buf, order) {
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+			if err != nil || cfg.Width*cfg.Height <= bestArea {
+				continue
+			}
+			best, bestArea = data, cfg.Width*cfg.Height
+		}
+	}
+	if best == nil {
+		return nil, apperror.UnsupportedFormat("RAW 解码需要支持 libraw 的 vips 或 ImageMagick（文件不含可用的内嵌预览）", nil)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(best))
+	if err != nil {
+		return nil, apperror.InvalidInput("无法解析 RAW 内嵌预览", err)
+	}
+	return orientNRGBA(toNRGBA(decoded), orientation), nil
+}
+
+func (ifd tiffIFD) jpegCandidates(buf []byte, order binary.ByteOrder) [][]byte {
+	var candidates [][]byte
+	slice := func(offset, length uint32) {
+		end := uint64(offset) + uint64(length)
+		if length < 4 || end > uint64(len(buf)) {
+			return
+		}
+		data := buf[offset:end]
+		if data[0] == 0xff && data[1] == 0xd8 {
+			candidates = append(candidates, data)
+		}
+	}
+	if offset, ok := ifd.uint(tiffTagJPEGOffset, order); ok {
+		if length, ok := ifd.uint(tiffTagJPEGLength, order); ok {
+			slice(offset, length)
+		}
+	}
+	if compression, ok := ifd.uint(tiffTagCompression, order); ok && (compression == 6 || compression == 7) {
+		offsets := ifd.uints(tiffTagStripOffsets, order)
+		counts := ifd.uints(tiffTagStripByteCount, order)
+		if len(offsets) == 1 && len(counts) == 1 {
+			slice(offsets[0], counts[0])
+		}
+	}
+	return candidates
+}
+
+// orientNRGBA 按 EXIF 方向值（1-8）旋转或镜像图像。
+func orientNRGBA(img *image.NRGBA, orientation uint32) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			src := img.PixOffset(x+img.Rect.Min.X, y+img.Rect.Min.Y)
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], img.Pix[src:src+4])
+		}
+	}
+	return dst
+}
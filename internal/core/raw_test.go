@@ -0,0 +1,92 @@
+package core
+
+import (
+	"encoding/binary"
+	"sort"
+	"testing"
+)
+
+type testTIFFEntry struct {
+	tag   uint16
+	typ   uint16
+	value uint32
+	text  string
+}
+
+// buildTestTIFF 生成小端 TIFF：IFD0 位于偏移 8，sub 非空时通过 SubIFDs 标签挂接一个子 IFD。
+func buildTestTIFF(ifd0, sub []testTIFFEntry) []byte {
+	buf := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	writeIFD := func(entries []testTIFFEntry) uint32 {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+		start := uint32(len(buf))
+		dataAt := start + 2 + uint32(len(entries))*12 + 4
+		ifd := binary.LittleEndian.AppendUint16(nil, uint16(len(entries)))
+		var data []byte
+		for _, e := range entries {
+			ifd = binary.LittleEndian.AppendUint16(ifd, e.tag)
+			ifd = binary.LittleEndian.AppendUint16(ifd, e.typ)
+			if e.typ == 2 {
+				text := append([]byte(e.text), 0)
+				ifd = binary.LittleEndian.AppendUint32(ifd, uint32(len(text)))
+				ifd = binary.LittleEndian.AppendUint32(ifd, dataAt+uint32(len(data)))
+				data = append(data, text...)
+				continue
+			}
+			ifd = binary.LittleEndian.AppendUint32(ifd, 1)
+			if e.typ == 3 {
+				ifd = binary.LittleEndian.AppendUint16(ifd, uint16(e.value))
+				ifd = append(ifd, 0, 0)
+			} else {
+				ifd = binary.LittleEndian.AppendUint32(ifd, e.value)
+			}
+		}
+		ifd = binary.LittleEndian.AppendUint32(ifd, 0)
+		buf = append(buf, ifd...)
+		buf = append(buf, data...)
+		return start
+	}
+	if len(sub) == 0 {
+		writeIFD(ifd0)
+		return buf
+	}
+	// 先占位写 IFD0，再写子 IFD 并回填 SubIFDs 偏移。
+	ifd0 = append(ifd0, testTIFFEntry{tag: tiffTagSubIFDs, typ: 4})
+	writeIFD(ifd0)
+	offset := writeIFD(sub)
+	count := int(binary.LittleEndian.Uint16(buf[8:10]))
+	for i := 0; i < count; i++ {
+		entry := buf[10+i*12 : 22+i*12]
+		if binary.LittleEndian.Uint16(entry) == tiffTagSubIFDs {
+			binary.LittleEndian.PutUint32(entry[8:], offset)
+		}
+	}
+	return buf
+}
+
+func TestRawKindRequiresRawMarkers(t *testing.T) {
+	nikon := testTIFFEntry{tag: tiffTagMake, typ: 2, text: "NIKON CORPORATION"}
+	sony := testTIFFEntry{tag: tiffTagMake, typ: 2, text: "SONY"}
+	rgb := testTIFFEntry{tag: tiffTagPhotometric, typ: 3, value: 2}
+	uncompressed := testTIFFEntry{tag: tiffTagCompression, typ: 3, value: 1}
+	for _, tc := range []struct {
+		name string
+		buf  []byte
+		want string
+	}{
+		{"nikon tiff", buildTestTIFF([]testTIFFEntry{nikon, rgb, uncompressed}, nil), ""},
+		{"sony tiff", buildTestTIFF([]testTIFFEntry{sony, rgb, uncompressed}, nil), ""},
+		{"nef compressed", buildTestTIFF([]testTIFFEntry{nikon}, []testTIFFEntry{
+			{tag: tiffTagCompression, typ: 3, value: tiffCompressionNEF},
+		}), "nef"},
+		{"nef uncompressed cfa", buildTestTIFF([]testTIFFEntry{nikon}, []testTIFFEntry{
+			uncompressed, {tag: tiffTagPhotometric, typ: 3, value: tiffPhotometricCFA},
+		}), "nef"},
+		{"arw compressed", buildTestTIFF([]testTIFFEntry{sony, {tag: tiffTagCompression, typ: 3, value: tiffCompressionARW}}, nil), "arw"},
+		{"arw private data", buildTestTIFF([]testTIFFEntry{sony, {tag: tiffTagDNGPrivateData, typ: 4, value: 1024}}, nil), "arw"},
+		{"nikon compression on sony", buildTestTIFF([]testTIFFEntry{sony, {tag: tiffTagCompression, typ: 3, value: tiffCompressionNEF}}, nil), ""},
+	} {
+		if got := rawKind(tc.buf); got != tc.want {
+			t.Errorf("%s: rawKind = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
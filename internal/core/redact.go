@@ -0,0 +1,279 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// TextBox 为 OCR 识别出的文本及其归一化坐标（0-1）。
+type TextBox struct {
+	Text string
+	X1   float64
+	Y1   float64
+	X2   float64
+	Y2   float64
+}
+
+// TextLocator 调用 OCR 服务识别 PNG 图像中的文本位置。
+type TextLocator func(pngData []byte) ([]TextBox, error)
+
+// RedactOptions 中 TextPattern 非空时需提供 Locator。
+type RedactOptions struct {
+	Rects       []image.Rectangle
+	TextPattern string
+	Locator     TextLocator
+	Method      string
+	Color       string
+	Strength    int
+	Quality     int
+	Conflict    string
+}
+
+// ParseRect 解析 "x,y,w,h" 形式的矩形。
+func ParseRect(value string) (image.Rectangle, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, apperror.InvalidArgument("区域格式应为 x,y,w,h", nil)
+	}
+	var nums [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return image.Rectangle{}, apperror.InvalidArgument("区域格式应为 x,y,w,h", err)
+		}
+		nums[i] = n
+	}
+	if nums[0] < 0 || nums[1] < 0 || nums[2] <= 0 || nums[3] <= 0 {
+		return image.Rectangle{}, apperror.InvalidArgument("区域坐标不能为负数且宽高必须为正数", nil)
+	}
+	return image.Rect(nums[0], nums[1], nums[0]+nums[2], nums[1]+nums[3]), nil
+}
+
+// Redact 对指定区域及匹配正则的 OCR 文本区域进行模糊、像素化或填充，动图逐帧处理。
+func Redact(inputPath, outputArg string, opts RedactOptions) (string, error) {
+	method := strings.ToLower(strings.TrimSpace(opts.Method))
+	if method == "" {
+		method = "pixelate"
+	}
+	if method != "blur" && method != "pixelate" && method != "fill" {
+		return "", apperror.InvalidArgument("遮挡方式仅支持 blur、pixelate 或 fill", nil)
+	}
+	if opts.Strength < 0 {
+		return "", apperror.InvalidArgument("遮挡强度不能为负数", nil)
+	}
+	fill := color.NRGBA{A: 255}
+	if method == "fill" && opts.Color != "" {
+		parsed, ok := parseColor(opts.Color)
+		if !ok {
+			return "", apperror.InvalidArgument("填充颜色无效", nil)
+		}
+		fill = parsed
+	}
+	var pattern *regexp.Regexp
+	if opts.TextPattern != "" {
+		compiled, err := regexp.Compile(opts.TextPattern)
+		if err != nil {
+			return "", apperror.InvalidArgument("文本正则无效", err)
+		}
+		pattern = compiled
+		if opts.Locator == nil {
+			return "", apperror.InvalidArgument("按文本遮挡需要 OCR 服务", nil)
+		}
+	}
+	if len(opts.Rects) == 0 && pattern == nil {
+		return "", apperror.InvalidArgument("至少需要指定 --rect 或 --text-pattern", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   FormatFromImageType(inputType),
+		Conflict:      opts.Conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", err
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	// OCR 在输出路径确定之后调用，输出冲突时不会产生服务请求；识别对象为解码后的首帧，
+	// 未匹配到文本且没有 --rect 时报错，避免写出未遮挡的副本。
+	var textRects []image.Rectangle
+	if pattern != nil {
+		pngBuf, err := encodePNG(img)
+		if err != nil {
+			return "", err
+		}
+		boxes, err := opts.Locator(pngBuf)
+		if err != nil {
+			return "", err
+		}
+		textRects = matchTextRegions(boxes, pattern, img.Rect.Dx(), img.Rect.Dy())
+		if len(textRects) == 0 && len(opts.Rects) == 0 {
+			return "", apperror.InvalidInput("OCR 未找到匹配的文本，未生成输出", nil)
+		}
+	}
+	transform := func(frame *image.NRGBA) (*image.NRGBA, error) {
+		rects := append(append([]image.Rectangle{}, opts.Rects...), textRects...)
+		dst := cloneNRGBA(frame)
+		for _, rect := range rects {
+			redactRegion(dst, rect.Intersect(dst.Rect), method, opts.Strength, fill)
+		}
+		return dst, nil
+	}
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, transform)
+	} else {
+		img, err = transform(img)
+		if err != nil {
+			return "", err
+		}
+		newImage, err = encodeRaster(img, outType, opts.Quality)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+// matchTextRegions 返回包含正则匹配的文本框像素区域。部分匹配时也遮挡整个文本框并外扩边距，
+// 因为 OCR 框内字符宽度不均，按字符比例截取容易漏出敏感内容。
+func matchTextRegions(boxes []TextBox, pattern *regexp.Regexp, width, height int) []image.Rectangle {
+	rects := []image.Rectangle{}
+	for _, box := range boxes {
+		if !containsMatch(pattern, box.Text) {
+			continue
+		}
+		left, top := box.X1*float64(width), box.Y1*float64(height)
+		right, bottom := box.X2*float64(width), box.Y2*float64(height)
+		pad := (bottom - top) * 0.25
+		rects = append(rects, image.Rect(
+			int(math.Floor(left-pad)), int(math.Floor(top-pad)),
+			int(math.Ceil(right+pad)), int(math.Ceil(bottom+pad)),
+		))
+	}
+	return rects
+}
+
+// containsMatch 判断文本中是否存在非空匹配。
+func containsMatch(pattern *regexp.Regexp, text string) bool {
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if loc[0] != loc[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func redactRegion(img *image.NRGBA, rect image.Rectangle, method string, strength int, fill color.NRGBA) {
+	if rect.Empty() {
+		return
+	}
+	short := minInt(rect.Dx(), rect.Dy())
+	switch method {
+	case "fill":
+		draw.Draw(img, rect, image.NewUniform(fill), image.Point{}, draw.Src)
+	case "blur":
+		radius := strength
+		if radius == 0 {
+			radius = maxInt(4, short/4)
+		}
+		boxBlurRegion(img, rect, radius)
+	default:
+		block := strength
+		if block == 0 {
+			block = maxInt(6, short/4)
+		}
+		pixelateNRGBA(img, rect, maxInt(2, block))
+	}
+}
+
+// boxBlurRegion 对区域做三次盒式模糊以近似高斯模糊，仅采样区域内像素，避免外部内容影响。
+func boxBlurRegion(img *image.NRGBA, rect image.Rectangle, radius int) {
+	w, h := rect.Dx(), rect.Dy()
+	buf := make([]float64, w*h*4)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			offset := img.PixOffset(rect.Min.X+x, rect.Min.Y+y)
+			for c := 0; c < 4; c++ {
+				buf[(y*w+x)*4+c] = float64(img.Pix[offset+c])
+			}
+		}
+	}
+	tmp := make([]float64, len(buf))
+	for pass := 0; pass < 3; pass++ {
+		blurLine(buf, tmp, w, h, radius, true)
+		blurLine(tmp, buf, w, h, radius, false)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			offset := img.PixOffset(rect.Min.X+x, rect.Min.Y+y)
+			for c := 0; c < 4; c++ {
+				img.Pix[offset+c] = clampByte(buf[(y*w+x)*4+c])
+			}
+		}
+	}
+}
+
+func blurLine(src, dst []float64, w, h, radius int, horizontal bool) {
+	lines, length := h, w
+	if !horizontal {
+		lines, length = w, h
+	}
+	index := func(line, pos int) int {
+		if horizontal {
+			return (line*w + pos) * 4
+		}
+		return (pos*w + line) * 4
+	}
+	for line := 0; line < lines; line++ {
+		for c := 0; c < 4; c++ {
+			sum := 0.0
+			count := 0
+			for pos := 0; pos <= minInt(radius, length-1); pos++ {
+				sum += src[index(line, pos)+c]
+				count++
+			}
+			for pos := 0; pos < length; pos++ {
+				dst[index(line, pos)+c] = sum / float64(count)
+				if add := pos + radius + 1; add < length {
+					sum += src[index(line, add)+c]
+					count++
+				}
+				if remove := pos - radius; remove >= 0 {
+					sum -= src[index(line, remove)+c]
+					count--
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// RemoveBgOptions 为本地抠图参数。Tolerance 为颜色容差百分比 (0-100)，Feather 为边缘羽化半径(px)，
+// Format 为输出路径无扩展名时使用的格式，MaskOnly 时输出灰度遮罩。
+type RemoveBgOptions struct {
+	Method    string
+	Tolerance float64
+	Feather   int
+	KeyColor  string
+	MaskOnly  bool
+	Format    string
+	Conflict  string
+}
+
+// CutoutOptions 为应用 AI 服务抠图结果的参数。
+type CutoutOptions struct {
+	MaskOnly bool
+	Format   string
+	Conflict string
+}
+
+// maxColorDistance 为 RGB 空间中黑到白的欧氏距离。
+var maxColorDistance = math.Sqrt(3 * 255 * 255)
+
+// RemoveBackground 在本地去除纯色背景：flood 从四周边缘泛洪填充，chroma 按键控色全图抠除。
+func RemoveBackground(inputPath, outputArg string, opts RemoveBgOptions) (string, error) {
+	method := strings.ToLower(strings.TrimSpace(opts.Method))
+	if method == "" {
+		method = "flood"
+	}
+	if method != "flood" && method != "chroma" {
+		return "", apperror.InvalidArgument("本地抠图方式仅支持 flood 或 chroma", nil)
+	}
+	if opts.Tolerance < 0 || opts.Tolerance > 100 {
+		return "", apperror.InvalidArgument("容差必须在 0-100 之间", nil)
+	}
+	if opts.Feather < 0 {
+		return "", apperror.InvalidArgument("羽化半径不能为负数", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, _, err = NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	outPath, outType, err := resolveCutoutOutput(inputPath, outputArg, opts.Format, opts.MaskOnly, opts.Conflict)
+	if err != nil {
+		return "", err
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	key, ok := parseColor(opts.KeyColor)
+	if !ok {
+		if opts.KeyColor != "" {
+			return "", apperror.InvalidArgument("背景颜色无效", nil)
+		}
+		key = borderColor(img)
+	}
+	tolerance := opts.Tolerance / 100 * maxColorDistance
+	var matte []float64
+	if method == "chroma" {
+		matte = chromaMatte(img, key, tolerance)
+	} else {
+		matte = floodMatte(img, key, tolerance)
+	}
+	matte = featherMatte(matte, img.Rect.Dx(), img.Rect.Dy(), opts.Feather)
+	if opts.MaskOnly {
+		img = matteImage(matte, img.Rect.Dx(), img.Rect.Dy())
+	} else {
+		applyMatte(img, matte)
+		if method == "chroma" {
+			despill(img, matte, key)
+		}
+	}
+	return writeCutout(outPath, img, outType)
+}
+
+// Segmenter 调用外部服务对 PNG 图像抠图，返回带透明通道的图像或灰度遮罩。
+type Segmenter func(pngData []byte) ([]byte, error)
+
+// ApplyCutout 先解析输出路径，再将解码后（已按 EXIF 方向校正）的图像以 PNG 交给 segment，并将结果应用到原图。结果尺寸与原图一致时直接采用其像素
+// （保留服务端的边缘处理），否则缩放遮罩后作用于原图。
+func ApplyCutout(inputPath, outputArg string, segment Segmenter, opts CutoutOptions) (string, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	outPath, outType, err := resolveCutoutOutput(inputPath, outputArg, opts.Format, opts.MaskOnly, opts.Conflict)
+	if err != nil {
+		return "", err
+	}
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	pngBuf, err := encodePNG(img)
+	if err != nil {
+		return "", err
+	}
+	result, err := segment(pngBuf)
+	if err != nil {
+		return "", err
+	}
+	cutout, err := decodeRaster(result)
+	if err != nil {
+		return "", apperror.New("E503", "无法解析抠图结果", err.Error(), err)
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	sameSize := cutout.Rect.Dx() == w && cutout.Rect.Dy() == h
+	if !sameSize {
+		cutout = scaleNRGBA(cutout, w, h)
+	}
+	matte := resultMatte(cutout)
+	switch {
+	case opts.MaskOnly:
+		img = matteImage(matte, w, h)
+	case sameSize && !cutout.Opaque():
+		img = cutout
+	default:
+		applyMatte(img, matte)
+	}
+	return writeCutout(outPath, img, outType)
+}
+
+// resultMatte 从服务结果中提取遮罩：带透明通道时取 alpha，否则视为灰度遮罩取亮度。
+func resultMatte(img *image.NRGBA) []float64 {
+	w := img.Rect.Dx()
+	matte := make([]float64, w*img.Rect.Dy())
+	useAlpha := !img.Opaque()
+	for i := range matte {
+		offset := img.PixOffset(i%w, i/w)
+		if useAlpha {
+			matte[i] = float64(img.Pix[offset+3]) / 255
+			continue
+		}
+		luma := 0.299*float64(img.Pix[offset]) + 0.587*float64(img.Pix[offset+1]) + 0.114*float64(img.Pix[offset+2])
+		matte[i] = luma / 255
+	}
+	return matte
+}
+
+func matteImage(matte []float64, w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i, value := range matte {
+		v := clampByte(value * 255)
+		img.Pix[i*4], img.Pix[i*4+1], img.Pix[i*4+2], img.Pix[i*4+3] = v, v, v, 255
+	}
+	return img
+}
+
+func writeCutout(outPath string, img *image.NRGBA, outType bimg.ImageType) (string, error) {
+	newImage, err := encodeRaster(img, outType, 0)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+		return "", apperror.ConfigError("无法写入输出文件", err)
+	}
+	return outPath, nil
+}
+
+// resolveCutoutOutput 解析抠图输出路径：输出为目录或无扩展名时使用 format（默认 png）。除遮罩外结果必须支持透明度。
+func resolveCutoutOutput(inputPath, outputArg, format string, maskOnly bool, conflict string) (string, bimg.ImageType, error) {
+	if format == "" {
+		format = "png"
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     inputPath,
+		OutputArg:     outputArg,
+		DesiredFormat: "",
+		InputFormat:   format,
+		Conflict:      conflict,
+		Overwrite:     false,
+	})
+	if err != nil {
+		return "", bimg.UNKNOWN, err
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return "", bimg.UNKNOWN, err
+	}
+	if outType == bimg.JPEG && !maskOnly {
+		return "", bimg.UNKNOWN, apperror.InvalidArgument("JPEG 不支持透明度，请输出 PNG/WebP", nil)
+	}
+	if !IsTypeSupportedSave(outType) {
+		return "", bimg.UNKNOWN, apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
+	}
+	return outPath, outType, nil
+}
+
+// borderColor 取四周边缘像素各通道的中位数作为背景色，可容忍边缘处少量前景。
+func borderColor(img *image.NRGBA) color.NRGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	var channels [3][]int
+	add := func(x, y int) {
+		offset := img.PixOffset(x, y)
+		for c := 0; c < 3; c++ {
+			channels[c] = append(channels[c], int(img.Pix[offset+c]))
+		}
+	}
+	for x := 0; x < w; x++ {
+		add(x, 0)
+		add(x, h-1)
+	}
+	for y := 1; y < h-1; y++ {
+		add(0, y)
+		add(w-1, y)
+	}
+	var median [3]uint8
+	for c := range channels {
+		sort.Ints(channels[c])
+		median[c] = uint8(channels[c][len(channels[c])/2])
+	}
+	return color.NRGBA{median[0], median[1], median[2], 255}
+}
+
+func colorDistance(pix []uint8, key color.NRGBA) float64 {
+	dr := float64(pix[0]) - float64(key.R)
+	dg := float64(pix[1]) - float64(key.G)
+	db := float64(pix[2]) - float64(key.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
+
+// floodMatte 从边缘开始泛洪，与背景色相近且连通的像素视为背景；返回前景覆盖率 (0-1)。
+func floodMatte(img *image.NRGBA, key color.NRGBA, tolerance float64) []float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	matte := make([]float64, w*h)
+	for i := range matte {
+		matte[i] = 1
+	}
+	isBackground := func(x, y int) bool {
+		offset := img.PixOffset(x, y)
+		return img.Pix[offset+3] == 0 || colorDistance(img.Pix[offset:offset+3], key) <= tolerance
+	}
+	queue := make([]image.Point, 0, 2*(w+h))
+	visit := func(x, y int) {
+		i := y*w + x
+		if matte[i] == 0 || !isBackground(x, y) {
+			return
+		}
+		matte[i] = 0
+		queue = append(queue, image.Pt(x, y))
+	}
+	for x := 0; x < w; x++ {
+		visit(x, 0)
+		visit(x, h-1)
+	}
+	for y := 0; y < h; y++ {
+		visit(0, y)
+		visit(w-1, y)
+	}
+	for len(queue) > 0 {
+		p := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if p.X > 0 {
+			visit(p.X-1, p.Y)
+		}
+		if p.X < w-1 {
+			visit(p.X+1, p.Y)
+		}
+		if p.Y > 0 {
+			visit(p.X, p.Y-1)
+		}
+		if p.Y < h-1 {
+			visit(p.X, p.Y+1)
+		}
+	}
+	return matte
+}
+
+// chromaMatte 按键控色抠除全图相近像素，容差外半倍范围内线性过渡以保留柔和边缘。
+func chromaMatte(img *image.NRGBA, key color.NRGBA, tolerance float64) []float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	matte := make([]float64, w*h)
+	ramp := math.Max(tolerance*0.5, 1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			offset := img.PixOffset(x, y)
+			d := colorDistance(img.Pix[offset:offset+3], key)
+			matte[y*w+x] = math.Min(math.Max((d-tolerance)/ramp, 0), 1)
+		}
+	}
+	return matte
+}
+
+// featherMatte 对遮罩做盒式模糊，使前景边缘产生 radius 像素的柔和过渡。
+func featherMatte(matte []float64, w, h, radius int) []float64 {
+	if radius <= 0 {
+		return matte
+	}
+	layer := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i, value := range matte {
+		layer.Pix[i*4+3] = clampByte(value * 255)
+	}
+	boxBlurRegion(layer, layer.Rect, radius)
+	feathered := make([]float64, len(matte))
+	for i := range feathered {
+		feathered[i] = float64(layer.Pix[i*4+3]) / 255
+	}
+	return feathered
+}
+
+func applyMatte(img *image.NRGBA, matte []float64) {
+	w := img.Rect.Dx()
+	for i, value := range matte {
+		offset := img.PixOffset(i%w, i/w)
+		img.Pix[offset+3] = clampByte(float64(img.Pix[offset+3]) * value)
+	}
+}
+
+// despill 抑制半透明边缘上的键控色溢色：键控色主通道不超过其余两通道的较大值。
+func despill(img *image.NRGBA, matte []float64, key color.NRGBA) {
+	channels := [3]uint8{key.R, key.G, key.B}
+	dominant := 0
+	for c := 1; c < 3; c++ {
+		if channels[c] > channels[dominant] {
+			dominant = c
+		}
+	}
+	low := minInt(int(channels[0]), minInt(int(channels[1]), int(channels[2])))
+	if int(channels[dominant])-low < 64 {
+		return
+	}
+	w := img.Rect.Dx()
+	for i, value := range matte {
+		if value <= 0 || value >= 1 {
+			continue
+		}
+		offset := img.PixOffset(i%w, i/w)
+		limit := uint8(0)
+		for c := 0; c < 3; c++ {
+			if c != dominant && img.Pix[offset+c] > limit {
+				limit = img.Pix[offset+c]
+			}
+		}
+		if img.Pix[offset+dominant] > limit {
+			img.Pix[offset+dominant] = limit
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyCutoutSendsDecodedPNG(t *testing.T) {
+	dir := t.TempDir()
+	src := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(input, jpg.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mask := image.NewGray(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 16; x < 32; x++ {
+			mask.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	var maskPNG bytes.Buffer
+	if err := png.Encode(&maskPNG, mask); err != nil {
+		t.Fatal(err)
+	}
+	var sent []byte
+	outPath, err := ApplyCutout(input, filepath.Join(dir, "cutout.png"), func(pngData []byte) ([]byte, error) {
+		sent = pngData
+		return maskPNG.Bytes(), nil
+	}, CutoutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(sent))
+	if err != nil || format != "png" || cfg.Width != 32 || cfg.Height != 16 {
+		t.Fatalf("segmenter received %s %dx%d (%v), want 32x16 png", format, cfg.Width, cfg.Height, err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := decodeRaster(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := out.NRGBAAt(4, 8).A; a != 0 {
+		t.Errorf("background alpha = %d, want 0", a)
+	}
+	if a := out.NRGBAAt(24, 8).A; a != 255 {
+		t.Errorf("foreground alpha = %d, want 255", a)
+	}
+}
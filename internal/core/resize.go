@@ -1,6 +1,7 @@
 package core
 
 import (
+	"image"
 	"math"
 	"os"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/h2non/bimg"
 	"github.com/kiry163/image-cli/pkg/apperror"
+	xdraw "golang.org/x/image/draw"
 )
 
 type ResizeOptions struct {
@@ -16,7 +18,9 @@ type ResizeOptions struct {
 	Fit                string
 	WithoutEnlargement bool
 	KeepRatio          bool
+	Quality            int
 	Conflict           string
+	Raw                RawOptions
 }
 
 func Resize(inputPath, outputArg string, opts ResizeOptions) (string, error) {
@@ -24,9 +28,9 @@ func Resize(inputPath, outputArg string, opts ResizeOptions) (string, error) {
 	if err != nil {
 		return "", apperror.InvalidInput("文件不存在或无法读取", err)
 	}
-	inputType := bimg.DetermineImageType(buf)
-	if inputType == bimg.UNKNOWN {
-		return "", apperror.UnsupportedFormat("无法识别输入格式", nil)
+	buf, inputType, err := normalizeImage(buf, opts.Raw)
+	if err != nil {
+		return "", err
 	}
 	inputFormat := FormatFromImageType(inputType)
 	outPath, outFormat, err := ResolveOutput(OutputSpec{
@@ -59,13 +63,14 @@ func Resize(inputPath, outputArg string, opts ResizeOptions) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if !bimg.IsTypeSupportedSave(outType) {
+	if !IsTypeSupportedSave(outType) {
 		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
 	}
 	options := bimg.Options{
 		Width:   width,
 		Height:  height,
 		Type:    outType,
+		Quality: opts.Quality,
 		Enlarge: !opts.WithoutEnlargement,
 	}
 	if err := applyFit(&options, opts.Fit); err != nil {
@@ -74,9 +79,17 @@ func Resize(inputPath, outputArg string, opts ResizeOptions) (string, error) {
 	if !opts.KeepRatio {
 		options.Force = true
 	}
-	newImage, err := bimg.NewImage(buf).Process(options)
-	if err != nil {
-		return "", apperror.InvalidInput("图像处理失败", err)
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, resizeFrameTransform(width, height, options))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		newImage, err = processImage(buf, options)
+		if err != nil {
+			return "", apperror.InvalidInput("图像处理失败", err)
+		}
 	}
 	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
 		return "", apperror.ConfigError("无法写入输出文件", err)
@@ -128,3 +141,76 @@ func applyFit(options *bimg.Options, fit string) error {
 	}
 	return nil
 }
+
+// resizeFrameTransform 按 bimg 选项的语义在 Go 中缩放动图的每一帧。
+func resizeFrameTransform(width, height int, options bimg.Options) frameTransform {
+	return func(frame *image.NRGBA) (*image.NRGBA, error) {
+		srcW := frame.Rect.Dx()
+		srcH := frame.Rect.Dy()
+		scaleX := float64(width) / float64(srcW)
+		scaleY := float64(height) / float64(srcH)
+		switch {
+		case width == 0:
+			scaleX = scaleY
+		case height == 0:
+			scaleY = scaleX
+		case options.Force:
+		case options.Crop:
+			scaleX = math.Max(scaleX, scaleY)
+			scaleY = scaleX
+		default:
+			scaleX = math.Min(scaleX, scaleY)
+			scaleY = scaleX
+		}
+		if !options.Enlarge && !options.Force {
+			scaleX = math.Min(scaleX, 1)
+			scaleY = math.Min(scaleY, 1)
+		}
+		scaledW := maxInt(1, int(math.Round(float64(srcW)*scaleX)))
+		scaledH := maxInt(1, int(math.Round(float64(srcH)*scaleY)))
+		scaled := scaleNRGBA(frame, scaledW, scaledH)
+		if width == 0 || height == 0 || options.Force {
+			return scaled, nil
+		}
+		if options.Crop && (scaledW > width || scaledH > height) {
+			cropW := minInt(width, scaledW)
+			cropH := minInt(height, scaledH)
+			left := (scaledW - cropW) / 2
+			top := (scaledH - cropH) / 2
+			cropped := image.NewNRGBA(image.Rect(0, 0, cropW, cropH))
+			xdraw.Draw(cropped, cropped.Bounds(), scaled, image.Point{X: left, Y: top}, xdraw.Src)
+			return cropped, nil
+		}
+		if options.Embed && (scaledW < width || scaledH < height) {
+			canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+			left := (width - scaledW) / 2
+			top := (height - scaledH) / 2
+			xdraw.Draw(canvas, image.Rect(left, top, left+scaledW, top+scaledH), scaled, image.Point{}, xdraw.Src)
+			return canvas, nil
+		}
+		return scaled, nil
+	}
+}
+
+func scaleNRGBA(src *image.NRGBA, width, height int) *image.NRGBA {
+	if src.Rect.Dx() == width && src.Rect.Dy() == height {
+		return cloneNRGBA(src)
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
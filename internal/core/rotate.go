@@ -1,6 +1,7 @@
 package core
 
 import (
+	"image"
 	"os"
 
 	"github.com/h2non/bimg"
@@ -11,6 +12,7 @@ type RotateOptions struct {
 	Degrees  int
 	Flip     bool
 	Flop     bool
+	Quality  int
 	Conflict string
 }
 
@@ -22,9 +24,9 @@ func Rotate(inputPath, outputArg string, opts RotateOptions) (string, error) {
 	if err != nil {
 		return "", apperror.InvalidInput("文件不存在或无法读取", err)
 	}
-	inputType := bimg.DetermineImageType(buf)
-	if inputType == bimg.UNKNOWN {
-		return "", apperror.UnsupportedFormat("无法识别输入格式", nil)
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
 	}
 	inputFormat := FormatFromImageType(inputType)
 	outPath, outFormat, err := ResolveOutput(OutputSpec{
@@ -42,7 +44,7 @@ func Rotate(inputPath, outputArg string, opts RotateOptions) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if !bimg.IsTypeSupportedSave(outType) {
+	if !IsTypeSupportedSave(outType) {
 		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
 	}
 	angle, err := parseAngle(opts.Degrees)
@@ -50,14 +52,23 @@ func Rotate(inputPath, outputArg string, opts RotateOptions) (string, error) {
 		return "", err
 	}
 	options := bimg.Options{
-		Type:   outType,
-		Rotate: angle,
-		Flip:   opts.Flip,
-		Flop:   opts.Flop,
+		Type:    outType,
+		Quality: opts.Quality,
+		Rotate:  angle,
+		Flip:    opts.Flip,
+		Flop:    opts.Flop,
 	}
-	newImage, err := bimg.NewImage(buf).Process(options)
-	if err != nil {
-		return "", apperror.InvalidInput("图像处理失败", err)
+	var newImage []byte
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		newImage, err = processAnimation(buf, outFormat, opts.Quality, rotateFrameTransform(angle, opts.Flip, opts.Flop))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		newImage, err = processImage(buf, options)
+		if err != nil {
+			return "", apperror.InvalidInput("图像处理失败", err)
+		}
 	}
 	if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
 		return "", apperror.ConfigError("无法写入输出文件", err)
@@ -80,3 +91,37 @@ func parseAngle(degrees int) (bimg.Angle, error) {
 		return bimg.D0, apperror.InvalidArgument("旋转角度仅支持 90/180/270/-90", nil)
 	}
 }
+
+// rotateFrameTransform 与 bimg 保持一致：先旋转，Flip 为上下翻转，Flop 为左右翻转。
+func rotateFrameTransform(angle bimg.Angle, flip, flop bool) frameTransform {
+	return func(frame *image.NRGBA) (*image.NRGBA, error) {
+		srcW := frame.Rect.Dx()
+		srcH := frame.Rect.Dy()
+		dstW, dstH := srcW, srcH
+		if angle == bimg.D90 || angle == bimg.D270 {
+			dstW, dstH = srcH, srcW
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+		for y := 0; y < srcH; y++ {
+			for x := 0; x < srcW; x++ {
+				dx, dy := x, y
+				switch angle {
+				case bimg.D90:
+					dx, dy = srcH-1-y, x
+				case bimg.D180:
+					dx, dy = srcW-1-x, srcH-1-y
+				case bimg.D270:
+					dx, dy = y, srcW-1-x
+				}
+				if flip {
+					dy = dstH - 1 - dy
+				}
+				if flop {
+					dx = dstW - 1 - dx
+				}
+				copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], frame.Pix[y*frame.Stride+x*4:y*frame.Stride+x*4+4])
+			}
+		}
+		return dst, nil
+	}
+}
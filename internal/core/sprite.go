@@ -0,0 +1,276 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+type SpriteOptions struct {
+	Format    string
+	Padding   int
+	Retina    bool
+	Style     string
+	Prefix    string
+	Overwrite bool
+	Conflict  string
+}
+
+type SpriteIcon struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+}
+
+type SpriteManifest struct {
+	Image  string       `json:"image"`
+	Retina string       `json:"retina,omitempty"`
+	Width  int          `json:"width"`
+	Height int          `json:"height"`
+	Icons  []SpriteIcon `json:"icons"`
+}
+
+type SpriteResult struct {
+	Image      string
+	Retina     string
+	Stylesheet string
+	Manifest   string
+}
+
+// Sprite 将多张图标装箱为雪碧图，并生成 CSS/SCSS 与 JSON 坐标清单。
+// Retina 模式下输入视为 2x 素材，同时输出 1x 与 @2x 两张图，坐标以 1x 为准。
+func Sprite(inputs []string, outputArg string, opts SpriteOptions) (SpriteResult, error) {
+	if len(inputs) == 0 {
+		return SpriteResult{}, apperror.InvalidArgument("至少需要一张输入图像", nil)
+	}
+	if opts.Padding < 0 {
+		return SpriteResult{}, apperror.InvalidArgument("间距不能为负数", nil)
+	}
+	style := strings.ToLower(strings.TrimSpace(opts.Style))
+	if style == "" {
+		style = "css"
+	}
+	if style != "css" && style != "scss" {
+		return SpriteResult{}, apperror.InvalidArgument("样式仅支持 css 或 scss", nil)
+	}
+	prefix := sanitizeIconName(opts.Prefix)
+	if prefix == "" {
+		prefix = "icon"
+	}
+	outPath, outFormat, err := ResolveOutput(OutputSpec{
+		InputPath:     "sprite.png",
+		OutputArg:     outputArg,
+		DesiredFormat: opts.Format,
+		InputFormat:   "png",
+		Conflict:      opts.Conflict,
+		Overwrite:     opts.Overwrite,
+	})
+	if err != nil {
+		return SpriteResult{}, err
+	}
+	if outFormat != "png" && outFormat != "webp" {
+		return SpriteResult{}, apperror.UnsupportedFormat("雪碧图仅支持输出 PNG 与 WebP", nil)
+	}
+	outType, err := ImageTypeFromFormat(outFormat)
+	if err != nil {
+		return SpriteResult{}, err
+	}
+
+	icons := make([]*image.NRGBA, 0, len(inputs))
+	names := make([]string, 0, len(inputs))
+	seen := map[string]int{}
+	sizes := make([]image.Point, 0, len(inputs))
+	scale := 1
+	if opts.Retina {
+		scale = 2
+	}
+	for _, input := range inputs {
+		buf, err := os.ReadFile(input)
+		if err != nil {
+			return SpriteResult{}, apperror.InvalidInput("文件不存在或无法读取: "+input, err)
+		}
+		if DetectImageType(buf) == bimg.UNKNOWN {
+			return SpriteResult{}, apperror.UnsupportedFormat("无法识别输入格式: "+input, nil)
+		}
+		img, err := decodeRaster(buf)
+		if err != nil {
+			return SpriteResult{}, err
+		}
+		icons = append(icons, img)
+		base := filepath.Base(input)
+		name := sanitizeIconName(strings.TrimSuffix(strings.TrimSuffix(base, filepath.Ext(base)), "@2x"))
+		if name == "" {
+			name = "image"
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+		names = append(names, name)
+		w := img.Rect.Dx() + opts.Padding*scale
+		h := img.Rect.Dy() + opts.Padding*scale
+		sizes = append(sizes, image.Pt(roundUp(w, scale), roundUp(h, scale)))
+	}
+
+	positions, sheetW, sheetH := packRects(sizes)
+	sheetW -= opts.Padding * scale
+	sheetH -= opts.Padding * scale
+	sheet := image.NewNRGBA(image.Rect(0, 0, roundUp(sheetW, scale), roundUp(sheetH, scale)))
+	manifest := SpriteManifest{
+		Width:  sheet.Rect.Dx() / scale,
+		Height: sheet.Rect.Dy() / scale,
+		Icons:  make([]SpriteIcon, 0, len(icons)),
+	}
+	for i, icon := range icons {
+		pos := positions[i]
+		draw.Draw(sheet, icon.Rect.Add(pos), icon, image.Point{}, draw.Src)
+		manifest.Icons = append(manifest.Icons, SpriteIcon{
+			Name:   names[i],
+			Source: filepath.Base(inputs[i]),
+			X:      pos.X / scale,
+			Y:      pos.Y / scale,
+			W:      (icon.Rect.Dx() + scale - 1) / scale,
+			H:      (icon.Rect.Dy() + scale - 1) / scale,
+		})
+	}
+
+	result := SpriteResult{Image: outPath}
+	if opts.Retina {
+		ext := filepath.Ext(outPath)
+		retinaPath, err := applyConflict(strings.TrimSuffix(outPath, ext)+"@2x"+ext, opts.Conflict, opts.Overwrite)
+		if err != nil {
+			return SpriteResult{}, err
+		}
+		if err := writeSpriteImage(retinaPath, sheet, outType); err != nil {
+			return SpriteResult{}, err
+		}
+		result.Retina = retinaPath
+		manifest.Retina = filepath.Base(retinaPath)
+		small := image.NewNRGBA(image.Rect(0, 0, manifest.Width, manifest.Height))
+		for i, icon := range icons {
+			entry := manifest.Icons[i]
+			scaled := scaleNRGBA(icon, entry.W, entry.H)
+			draw.Draw(small, scaled.Rect.Add(image.Pt(entry.X, entry.Y)), scaled, image.Point{}, draw.Src)
+		}
+		sheet = small
+	}
+	if err := writeSpriteImage(outPath, sheet, outType); err != nil {
+		return SpriteResult{}, err
+	}
+	manifest.Image = filepath.Base(outPath)
+
+	stem := strings.TrimSuffix(outPath, filepath.Ext(outPath))
+	stylePath, err := applyConflict(stem+"."+style, opts.Conflict, opts.Overwrite)
+	if err != nil {
+		return SpriteResult{}, err
+	}
+	stylesheet := spriteCSS(manifest, prefix)
+	if style == "scss" {
+		stylesheet = spriteSCSS(manifest, prefix)
+	}
+	if err := os.WriteFile(stylePath, []byte(stylesheet), 0o644); err != nil {
+		return SpriteResult{}, apperror.ConfigError("无法写入样式文件", err)
+	}
+	result.Stylesheet = stylePath
+
+	manifestPath, err := applyConflict(stem+".json", opts.Conflict, opts.Overwrite)
+	if err != nil {
+		return SpriteResult{}, err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return SpriteResult{}, apperror.ConfigError("清单编码失败", err)
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0o644); err != nil {
+		return SpriteResult{}, apperror.ConfigError("无法写入清单文件", err)
+	}
+	result.Manifest = manifestPath
+	return result, nil
+}
+
+func writeSpriteImage(path string, img *image.NRGBA, outType bimg.ImageType) error {
+	data, err := encodeRaster(img, outType, 100)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return apperror.ConfigError("无法写入输出文件", err)
+	}
+	return nil
+}
+
+func spriteCSS(manifest SpriteManifest, prefix string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".%s {\n  display: inline-block;\n  background-image: url(\"%s\");\n  background-repeat: no-repeat;\n}\n", prefix, manifest.Image)
+	for _, icon := range manifest.Icons {
+		fmt.Fprintf(&b, "\n.%s-%s {\n  width: %dpx;\n  height: %dpx;\n  background-position: %s %s;\n}\n",
+			prefix, icon.Name, icon.W, icon.H, cssOffset(icon.X), cssOffset(icon.Y))
+	}
+	if manifest.Retina != "" {
+		fmt.Fprintf(&b, "\n@media (-webkit-min-device-pixel-ratio: 2), (min-resolution: 192dpi) {\n  .%s {\n    background-image: url(\"%s\");\n    background-size: %dpx %dpx;\n  }\n}\n",
+			prefix, manifest.Retina, manifest.Width, manifest.Height)
+	}
+	return b.String()
+}
+
+func spriteSCSS(manifest SpriteManifest, prefix string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$%s-sprite-image: \"%s\";\n", prefix, manifest.Image)
+	if manifest.Retina != "" {
+		fmt.Fprintf(&b, "$%s-sprite-retina: \"%s\";\n", prefix, manifest.Retina)
+	}
+	fmt.Fprintf(&b, "$%s-sprite-width: %dpx;\n$%s-sprite-height: %dpx;\n\n", prefix, manifest.Width, prefix, manifest.Height)
+	fmt.Fprintf(&b, "$%s-sprites: (\n", prefix)
+	for _, icon := range manifest.Icons {
+		fmt.Fprintf(&b, "  \"%s\": (%s, %s, %dpx, %dpx),\n", icon.Name, cssOffset(icon.X), cssOffset(icon.Y), icon.W, icon.H)
+	}
+	b.WriteString(");\n\n")
+	fmt.Fprintf(&b, "@mixin %s-sprite($name) {\n  $sprite: map-get($%s-sprites, $name);\n  width: nth($sprite, 3);\n  height: nth($sprite, 4);\n  background-position: nth($sprite, 1) nth($sprite, 2);\n}\n\n", prefix, prefix)
+	fmt.Fprintf(&b, ".%s {\n  display: inline-block;\n  background-image: url($%s-sprite-image);\n  background-repeat: no-repeat;\n", prefix, prefix)
+	if manifest.Retina != "" {
+		fmt.Fprintf(&b, "\n  @media (-webkit-min-device-pixel-ratio: 2), (min-resolution: 192dpi) {\n    background-image: url($%s-sprite-retina);\n    background-size: $%s-sprite-width $%s-sprite-height;\n  }\n", prefix, prefix, prefix)
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "@each $name, $sprite in $%s-sprites {\n  .%s-#{$name} {\n    @include %s-sprite($name);\n  }\n}\n", prefix, prefix, prefix)
+	return b.String()
+}
+
+func cssOffset(value int) string {
+	if value == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("-%dpx", value)
+}
+
+func sanitizeIconName(name string) string {
+	var b strings.Builder
+	lastDash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+			lastDash = false
+		case !lastDash && b.Len() > 0:
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func roundUp(value, multiple int) int {
+	if multiple <= 1 {
+		return value
+	}
+	return (value + multiple - 1) / multiple * multiple
+}
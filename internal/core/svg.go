@@ -0,0 +1,173 @@
+package core
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+// libvips 以 72 DPI 渲染 SVG，1 个用户单位对应 1 像素。
+const svgBaseDPI = 72
+
+var (
+	svgAttrPattern   = regexp.MustCompile(`([\w:-]+)\s*=\s*("[^"]*"|'[^']*')`)
+	svgLengthPattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*([a-z%]*)$`)
+	svgUnits         = map[string]float64{
+		"":   1,
+		"px": 1,
+		"pt": 1,
+		"pc": 12,
+		"in": svgBaseDPI,
+		"cm": svgBaseDPI / 2.54,
+		"mm": svgBaseDPI / 25.4,
+	}
+)
+
+type svgRoot struct {
+	start   int
+	end     int
+	attrs   string
+	width   float64
+	height  float64
+	viewBox string
+}
+
+// parseSVGRoot 定位根 <svg> 标签并计算其固有尺寸（像素）。
+func parseSVGRoot(buf []byte) (svgRoot, error) {
+	start := bytes.Index(buf, []byte("<svg"))
+	if start < 0 {
+		return svgRoot{}, apperror.InvalidInput("无法解析 SVG", nil)
+	}
+	end := -1
+	var quote byte
+	for i := start + 4; i < len(buf); i++ {
+		c := buf[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+		} else if c == '>' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return svgRoot{}, apperror.InvalidInput("无法解析 SVG", nil)
+	}
+	root := svgRoot{start: start, end: end, attrs: string(buf[start+4 : end])}
+	var widthAttr, heightAttr string
+	for _, match := range svgAttrPattern.FindAllStringSubmatch(root.attrs, -1) {
+		value := strings.TrimSpace(match[2][1 : len(match[2])-1])
+		switch match[1] {
+		case "width":
+			widthAttr = value
+		case "height":
+			heightAttr = value
+		case "viewBox":
+			root.viewBox = value
+		}
+	}
+	var boxW, boxH float64
+	if fields := strings.FieldsFunc(root.viewBox, func(r rune) bool { return r == ' ' || r == ',' }); len(fields) == 4 {
+		boxW, _ = strconv.ParseFloat(fields[2], 64)
+		boxH, _ = strconv.ParseFloat(fields[3], 64)
+	}
+	width, widthOK := parseSVGLength(widthAttr)
+	height, heightOK := parseSVGLength(heightAttr)
+	switch {
+	case widthOK && heightOK:
+	case widthOK && boxW > 0 && boxH > 0:
+		height = width * boxH / boxW
+	case heightOK && boxW > 0 && boxH > 0:
+		width = height * boxW / boxH
+	case boxW > 0 && boxH > 0:
+		width, height = boxW, boxH
+	default:
+		return svgRoot{}, apperror.InvalidInput("无法确定 SVG 尺寸", nil)
+	}
+	root.width, root.height = width, height
+	return root, nil
+}
+
+func parseSVGLength(value string) (float64, bool) {
+	match := svgLengthPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, false
+	}
+	unit, ok := svgUnits[match[2]]
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number * unit, true
+}
+
+// SVGSize 返回 SVG 的固有像素尺寸。
+func SVGSize(buf []byte) (int, int, error) {
+	root, err := parseSVGRoot(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+	return maxInt(1, int(math.Round(root.width))), maxInt(1, int(math.Round(root.height))), nil
+}
+
+// svgRenderSize 按 DPI 或目标宽高计算渲染尺寸；同时指定宽高时等比缩放至不超过该范围。
+func svgRenderSize(intrinsicW, intrinsicH float64, density, width, height int) (int, int) {
+	scale := 1.0
+	switch {
+	case width > 0 && height > 0:
+		scale = math.Min(float64(width)/intrinsicW, float64(height)/intrinsicH)
+	case width > 0:
+		scale = float64(width) / intrinsicW
+	case height > 0:
+		scale = float64(height) / intrinsicH
+	case density > 0:
+		scale = float64(density) / svgBaseDPI
+	}
+	return maxInt(1, int(math.Round(intrinsicW*scale))), maxInt(1, int(math.Round(intrinsicH*scale)))
+}
+
+// renderSVGAt 改写根标签的 width/height，使 libvips 直接按目标尺寸栅格化矢量图。
+func renderSVGAt(buf []byte, density, width, height int) ([]byte, error) {
+	root, err := parseSVGRoot(buf)
+	if err != nil {
+		return nil, err
+	}
+	targetW, targetH := svgRenderSize(root.width, root.height, density, width, height)
+	attrs := svgAttrPattern.ReplaceAllStringFunc(root.attrs, func(attr string) string {
+		name := svgAttrPattern.FindStringSubmatch(attr)[1]
+		if name == "width" || name == "height" {
+			return ""
+		}
+		return attr
+	})
+	attrs = strings.TrimRight(attrs, " \t\r\n/")
+	selfClosing := strings.HasSuffix(strings.TrimSpace(root.attrs), "/")
+	var tag strings.Builder
+	tag.WriteString("<svg")
+	tag.WriteString(attrs)
+	tag.WriteString(` width="` + strconv.Itoa(targetW) + `" height="` + strconv.Itoa(targetH) + `"`)
+	if root.viewBox == "" {
+		tag.WriteString(` viewBox="0 0 ` + strconv.FormatFloat(root.width, 'f', -1, 64) + " " + strconv.FormatFloat(root.height, 'f', -1, 64) + `"`)
+	}
+	if selfClosing {
+		tag.WriteString("/")
+	}
+	tag.WriteString(">")
+	out := make([]byte, 0, len(buf)+64)
+	out = append(out, buf[:root.start]...)
+	out = append(out, tag.String()...)
+	out = append(out, buf[root.end+1:]...)
+	return out, nil
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+type TemplateContext struct {
+	InputPath string
+	Index     int
+	Width     int
+	Height    int
+	EXIF      bimg.EXIF
+	Now       time.Time
+	Vars      map[string]string
+}
+
+func HasTemplate(text string) bool {
+	return strings.Contains(text, "{")
+}
+
+func NewTemplateContext(inputPath string, index int) (TemplateContext, error) {
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return TemplateContext{}, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, _, err = NormalizeImage(buf)
+	if err != nil {
+		return TemplateContext{}, err
+	}
+	meta, err := bimg.Metadata(buf)
+	if err != nil {
+		return TemplateContext{}, apperror.InvalidInput("无法解析图像", err)
+	}
+	return TemplateContext{
+		InputPath: inputPath,
+		Index:     index,
+		Width:     meta.Size.Width,
+		Height:    meta.Size.Height,
+		EXIF:      meta.EXIF,
+		Now:       time.Now(),
+	}, nil
+}
+
+// ValidateTemplate 只检查模板语法与变量名，不解析取值（如环境变量），取值在逐个文件展开时进行。
+func ValidateTemplate(text string) error {
+	if !HasTemplate(text) {
+		return nil
+	}
+	_, err := walkTemplate(text, func(name string) (string, error) {
+		return "", checkTemplateVar(name)
+	})
+	return err
+}
+
+func ExpandTemplate(text string, ctx TemplateContext) (string, error) {
+	return walkTemplate(text, func(name string) (string, error) {
+		return templateValue(name, ctx)
+	})
+}
+
+func walkTemplate(text string, resolve func(name string) (string, error)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '{' && i+1 < len(text) && text[i+1] == '{' {
+			b.WriteByte('{')
+			i++
+			continue
+		}
+		if c == '}' && i+1 < len(text) && text[i+1] == '}' {
+			b.WriteByte('}')
+			i++
+			continue
+		}
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(text[i+1:], '}')
+		if end < 0 {
+			return "", apperror.InvalidArgument("模板变量缺少 }", nil)
+		}
+		name := text[i+1 : i+1+end]
+		value, err := resolve(name)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		i += end + 1
+	}
+	return b.String(), nil
+}
+
+func templateValue(name string, ctx TemplateContext) (string, error) {
+	key, arg, hasArg := strings.Cut(strings.TrimSpace(name), ":")
+	if value, ok := ctx.Vars[key]; ok && !hasArg && !IsReservedTemplateVar(key) {
+		return value, nil
+	}
+	switch {
+	case key == "filename" && !hasArg:
+		return filepath.Base(ctx.InputPath), nil
+	case key == "stem" && !hasArg:
+		base := filepath.Base(ctx.InputPath)
+		return strings.TrimSuffix(base, filepath.Ext(base)), nil
+	case key == "ext" && !hasArg:
+		return strings.TrimPrefix(filepath.Ext(ctx.InputPath), "."), nil
+	case key == "index":
+		if !hasArg {
+			return strconv.Itoa(ctx.Index), nil
+		}
+		width, err := strconv.Atoi(arg)
+		if err != nil || width <= 0 {
+			return "", apperror.InvalidArgument("模板变量 index 宽度无效: "+name, err)
+		}
+		value := strconv.Itoa(ctx.Index)
+		if len(value) < width {
+			value = strings.Repeat("0", width-len(value)) + value
+		}
+		return value, nil
+	case key == "width" && !hasArg:
+		return strconv.Itoa(ctx.Width), nil
+	case key == "height" && !hasArg:
+		return strconv.Itoa(ctx.Height), nil
+	case key == "date":
+		now := ctx.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		layout := "YYYY-MM-DD"
+		if hasArg && arg != "" {
+			layout = arg
+		}
+		return formatDate(layout, now)
+	case strings.HasPrefix(key, "exif.") && !hasArg:
+		return exifValue(ctx.EXIF, strings.TrimPrefix(key, "exif."))
+	case strings.HasPrefix(key, "env.") && !hasArg:
+		envName := strings.TrimPrefix(key, "env.")
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			return "", apperror.InvalidArgument("环境变量未设置: "+envName, nil)
+		}
+		return value, nil
+	default:
+		return "", apperror.InvalidArgument("未知模板变量: {"+name+"}", nil)
+	}
+}
+
+func exifValue(exif bimg.EXIF, field string) (string, error) {
+	value := reflect.ValueOf(exif)
+	fieldValue := value.FieldByNameFunc(func(name string) bool {
+		return strings.EqualFold(name, field)
+	})
+	if !fieldValue.IsValid() {
+		return "", apperror.InvalidArgument("未知 EXIF 字段: "+field, nil)
+	}
+	switch fieldValue.Kind() {
+	case reflect.String:
+		return fieldValue.String(), nil
+	case reflect.Int:
+		return strconv.FormatInt(fieldValue.Int(), 10), nil
+	default:
+		return "", apperror.InvalidArgument("EXIF 字段不支持: "+field, nil)
+	}
+}
+
+// IsReservedTemplateVar 判断名称是否为内置模板变量（含 exif. 与 env. 前缀），这类名称不能由数据列覆盖。
+func IsReservedTemplateVar(name string) bool {
+	switch name {
+	case "filename", "stem", "ext", "index", "width", "height", "date":
+		return true
+	}
+	return strings.HasPrefix(name, "exif.") || strings.HasPrefix(name, "env.")
+}
+
+// checkTemplateVar 静态检查变量名与参数，不读取图像、EXIF 或环境变量。
+func checkTemplateVar(name string) error {
+	key, arg, hasArg := strings.Cut(strings.TrimSpace(name), ":")
+	switch {
+	case !hasArg && (key == "filename" || key == "stem" || key == "ext" || key == "width" || key == "height"):
+		return nil
+	case key == "index":
+		if !hasArg {
+			return nil
+		}
+		if width, err := strconv.Atoi(arg); err != nil || width <= 0 {
+			return apperror.InvalidArgument("模板变量 index 宽度无效: "+name, err)
+		}
+		return nil
+	case key == "date":
+		if !hasArg || arg == "" {
+			return nil
+		}
+		_, err := formatDate(arg, time.Time{})
+		return err
+	case strings.HasPrefix(key, "exif.") && !hasArg:
+		_, err := exifValue(bimg.EXIF{}, strings.TrimPrefix(key, "exif."))
+		return err
+	case strings.HasPrefix(key, "env.") && !hasArg && key != "env.":
+		return nil
+	default:
+		return apperror.InvalidArgument("未知模板变量: {"+name+"}", nil)
+	}
+}
+
+// dateTokens 为日期格式支持的占位符，按长度优先匹配；其余 ASCII 字母视为未知占位符。
+var dateTokens = []struct {
+	token  string
+	format func(t time.Time) string
+}{
+	{"YYYY", func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) }},
+	{"YY", func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()%100) }},
+	{"MM", func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) }},
+	{"DD", func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) }},
+	{"HH", func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) }},
+	{"mm", func(t time.Time) string { return fmt.Sprintf("%02d", t.Minute()) }},
+	{"ss", func(t time.Time) string { return fmt.Sprintf("%02d", t.Second()) }},
+}
+
+// formatDate 按 YYYY/YY/MM/DD/HH/mm/ss 占位符格式化日期，其他非字母字符（含中文）原样输出。
+func formatDate(layout string, t time.Time) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		matched := false
+		for _, token := range dateTokens {
+			if strings.HasPrefix(layout[i:], token.token) {
+				b.WriteString(token.format(t))
+				i += len(token.token)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		c := layout[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+			return "", apperror.InvalidArgument("日期格式占位符无效: "+layout+"，仅支持 YYYY YY MM DD HH mm ss", nil)
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), nil
+}
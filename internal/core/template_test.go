@@ -0,0 +1,86 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	now := time.Date(2024, time.March, 7, 9, 5, 3, 0, time.UTC)
+	cases := map[string]string{
+		"YYYY-MM-DD":      "2024-03-07",
+		"YY/MM/DD HH:mm":  "24/03/07 09:05",
+		"YYYY年MM月DD日":     "2024年03月07日",
+		"YYYYMMDD-HHmmss": "20240307-090503",
+	}
+	for layout, want := range cases {
+		got, err := formatDate(layout, now)
+		if err != nil || got != want {
+			t.Errorf("formatDate(%q) = %q, %v; want %q", layout, got, err, want)
+		}
+	}
+	for _, layout := range []string{"MMM", "YYYY-M-D", "ddd", "HHmmss_v2"} {
+		if _, err := formatDate(layout, now); err == nil {
+			t.Errorf("formatDate(%q) accepted unknown token", layout)
+		}
+	}
+}
+
+func TestValidateTemplateDoesNotResolveValues(t *testing.T) {
+	if err := ValidateTemplate("{env.IMAGE_CLI_TEMPLATE_UNSET} {index:3} {date:YYYY} {exif.Model}"); err != nil {
+		t.Fatalf("ValidateTemplate: %v", err)
+	}
+	for _, text := range []string{"{unknown}", "{index:x}", "{date:MMM}", "{exif.NoSuchField}", "{env.}", "{stem"} {
+		if err := ValidateTemplate(text); err == nil {
+			t.Errorf("ValidateTemplate(%q) = nil, want error", text)
+		}
+	}
+}
+
+func TestExpandTemplateEnv(t *testing.T) {
+	t.Setenv("IMAGE_CLI_TEMPLATE_TEST", "acme")
+	got, err := ExpandTemplate("{{{env.IMAGE_CLI_TEMPLATE_TEST}}} #{index:3}", TemplateContext{Index: 7})
+	if err != nil || got != "{acme} #007" {
+		t.Fatalf("ExpandTemplate = %q, %v", got, err)
+	}
+	if _, err := ExpandTemplate("{env.IMAGE_CLI_TEMPLATE_UNSET}", TemplateContext{}); err == nil {
+		t.Fatal("unset env should fail at expansion time")
+	}
+}
+
+func TestTemplateBuiltinsNotShadowedByVars(t *testing.T) {
+	ctx := TemplateContext{Index: 3, Vars: map[string]string{"index": "x", "name": "Ada"}}
+	got, err := ExpandTemplate("{name}-{index}", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Ada-3" {
+		t.Errorf("ExpandTemplate = %q, want %q", got, "Ada-3")
+	}
+}
+
+func TestReadComposeRowsRejectsReservedColumns(t *testing.T) {
+	dir := t.TempDir()
+	for _, column := range []string{"filename", "index", "date", "exif.Make", "env.HOME"} {
+		path := filepath.Join(dir, "data.csv")
+		if err := os.WriteFile(path, []byte("name,"+column+"\nAda,1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readComposeRows(path); err == nil {
+			t.Errorf("column %q: expected error", column)
+		}
+	}
+	path := filepath.Join(dir, "ok.csv")
+	if err := os.WriteFile(path, []byte("\ufeffname, title\nAda,Dr\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readComposeRows(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0]["name"] != "Ada" || rows[0]["title"] != "Dr" {
+		t.Errorf("rows = %v", rows)
+	}
+}
@@ -184,6 +184,13 @@ func parseColor(value string) (color.NRGBA, bool) {
 	}
 	if strings.HasPrefix(value, "#") {
 		hex := strings.TrimPrefix(value, "#")
+		if len(hex) == 3 || len(hex) == 4 {
+			expanded := make([]byte, 0, len(hex)*2)
+			for i := 0; i < len(hex); i++ {
+				expanded = append(expanded, hex[i], hex[i])
+			}
+			hex = string(expanded)
+		}
 		if len(hex) == 6 || len(hex) == 8 {
 			r, _ := parseHexByte(hex[0:2])
 			g, _ := parseHexByte(hex[2:4])
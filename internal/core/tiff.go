@@ -0,0 +1,141 @@
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"sort"
+)
+
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+type tiffEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	data  []byte
+}
+
+// writeMultiPageTIFF 将多张图像写为 Deflate 压缩的多页 TIFF，dpi 写入分辨率标签。
+func writeMultiPageTIFF(pages []*image.NRGBA, dpi int) ([]byte, error) {
+	order := binary.LittleEndian
+	var buf bytes.Buffer
+	buf.WriteString("II")
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(0))
+	prevNext := 4
+	for index, page := range pages {
+		samples := 3
+		if !page.Opaque() {
+			samples = 4
+		}
+		strip, err := deflatePixels(page, samples)
+		if err != nil {
+			return nil, err
+		}
+		stripOffset := buf.Len()
+		buf.Write(strip)
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+		width := uint32(page.Rect.Dx())
+		height := uint32(page.Rect.Dy())
+		bits := make([]uint16, samples)
+		for i := range bits {
+			bits[i] = 8
+		}
+		entries := []tiffEntry{
+			tiffLongEntry(254, 2),
+			tiffLongEntry(256, width),
+			tiffLongEntry(257, height),
+			tiffShortEntry(258, bits...),
+			tiffShortEntry(259, 8),
+			tiffShortEntry(262, 2),
+			tiffLongEntry(273, uint32(stripOffset)),
+			tiffShortEntry(277, uint16(samples)),
+			tiffLongEntry(278, height),
+			tiffLongEntry(279, uint32(len(strip))),
+			tiffRationalEntry(282, uint32(dpi), 1),
+			tiffRationalEntry(283, uint32(dpi), 1),
+			tiffShortEntry(284, 1),
+			tiffShortEntry(296, 2),
+			tiffShortEntry(297, uint16(index), uint16(len(pages))),
+		}
+		if samples == 4 {
+			entries = append(entries, tiffShortEntry(338, 2))
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+		ifdOffset := buf.Len()
+		order.PutUint32(buf.Bytes()[prevNext:prevNext+4], uint32(ifdOffset))
+		extraOffset := ifdOffset + 2 + len(entries)*12 + 4
+		var extra bytes.Buffer
+		binary.Write(&buf, order, uint16(len(entries)))
+		for _, entry := range entries {
+			binary.Write(&buf, order, entry.tag)
+			binary.Write(&buf, order, entry.kind)
+			binary.Write(&buf, order, entry.count)
+			if len(entry.data) <= 4 {
+				value := make([]byte, 4)
+				copy(value, entry.data)
+				buf.Write(value)
+				continue
+			}
+			binary.Write(&buf, order, uint32(extraOffset+extra.Len()))
+			extra.Write(entry.data)
+		}
+		prevNext = buf.Len()
+		binary.Write(&buf, order, uint32(0))
+		buf.Write(extra.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+func deflatePixels(img *image.NRGBA, samples int) ([]byte, error) {
+	var out bytes.Buffer
+	writer := zlib.NewWriter(&out)
+	row := make([]byte, img.Rect.Dx()*samples)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		line := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		if samples == 4 {
+			copy(row, line)
+		} else {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				copy(row[x*3:x*3+3], line[x*4:x*4+3])
+			}
+		}
+		if _, err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func tiffShortEntry(tag uint16, values ...uint16) tiffEntry {
+	data := make([]byte, len(values)*2)
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(data[i*2:], v)
+	}
+	return tiffEntry{tag: tag, kind: tiffShort, count: uint32(len(values)), data: data}
+}
+
+func tiffLongEntry(tag uint16, value uint32) tiffEntry {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, value)
+	return tiffEntry{tag: tag, kind: tiffLong, count: 1, data: data}
+}
+
+func tiffRationalEntry(tag uint16, num, den uint32) tiffEntry {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, num)
+	binary.LittleEndian.PutUint32(data[4:], den)
+	return tiffEntry{tag: tag, kind: tiffRational, count: 1, data: data}
+}
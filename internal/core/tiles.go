@@ -0,0 +1,364 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/h2non/bimg"
+	"github.com/kiry163/image-cli/pkg/apperror"
+)
+
+type TilesOptions struct {
+	TileSize  int
+	Overlap   int
+	Layout    string
+	Format    string
+	Quality   int
+	Overwrite bool
+	Conflict  string
+}
+
+type GridOptions struct {
+	Grid      string
+	Format    string
+	Quality   int
+	Overwrite bool
+	Conflict  string
+}
+
+type googleDescriptor struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	TileSize int    `json:"tileSize"`
+	MinZoom  int    `json:"minZoom"`
+	MaxZoom  int    `json:"maxZoom"`
+	Format   string `json:"format"`
+}
+
+// Tiles 生成 DZI/Zoomify/Google 布局的缩放金字塔，返回描述文件路径。
+// 优先使用 vips dzsave 以支持超大图像，不可用时在内存中生成。
+func Tiles(inputPath, outputDir string, opts TilesOptions) (string, error) {
+	layout := strings.ToLower(strings.TrimSpace(opts.Layout))
+	if layout == "" || layout == "dz" {
+		layout = "dzi"
+	}
+	if layout != "dzi" && layout != "zoomify" && layout != "google" {
+		return "", apperror.InvalidArgument("布局仅支持 dzi、zoomify 或 google", nil)
+	}
+	if opts.TileSize <= 0 || opts.TileSize > 8192 {
+		return "", apperror.InvalidArgument("瓦片尺寸必须在 1-8192 之间", nil)
+	}
+	if opts.Overlap < 0 || opts.Overlap*2 >= opts.TileSize {
+		return "", apperror.InvalidArgument("重叠像素无效", nil)
+	}
+	if layout != "dzi" {
+		opts.Overlap = 0
+	}
+	format := NormalizeFormat(opts.Format)
+	if format == "" {
+		format = "jpg"
+	}
+	if format != "jpg" && format != "png" && format != "webp" {
+		return "", apperror.UnsupportedFormat("瓦片仅支持 jpg、png 或 webp", nil)
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return "", apperror.ConfigError("无法创建输出目录", err)
+	}
+	base := filepath.Base(inputPath)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	target := filepath.Join(outputDir, stem)
+	if layout == "dzi" {
+		target += ".dzi"
+	}
+	target, err = applyConflict(target, opts.Conflict, opts.Overwrite)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(target, ".dzi")
+	if opts.Overwrite || opts.Conflict == "overwrite" {
+		if layout == "dzi" {
+			os.RemoveAll(name + "_files")
+		} else {
+			os.RemoveAll(name)
+		}
+	}
+
+	var descriptor string
+	if cmdPath, ok := VipsCommand(); ok && !isExtendedType(inputType) {
+		descriptor, err = vipsTiles(cmdPath, inputPath, name, layout, format, opts)
+	} else {
+		descriptor, err = goTiles(buf, name, layout, format, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	if layout == "google" {
+		size, err := bimg.Size(buf)
+		if err != nil {
+			return "", apperror.InvalidInput("无法读取图像尺寸", err)
+		}
+		descriptor = filepath.Join(name, "tiles.json")
+		data, _ := json.MarshalIndent(googleDescriptor{
+			Width:    size.Width,
+			Height:   size.Height,
+			TileSize: opts.TileSize,
+			MaxZoom:  zoomLevels(size.Width, size.Height, opts.TileSize) - 1,
+			Format:   format,
+		}, "", "  ")
+		if err := os.WriteFile(descriptor, append(data, '\n'), 0o644); err != nil {
+			return "", apperror.ConfigError("无法写入描述文件", err)
+		}
+	}
+	return descriptor, nil
+}
+
+func vipsTiles(cmdPath, inputPath, name, layout, format string, opts TilesOptions) (string, error) {
+	vipsLayout := layout
+	if layout == "dzi" {
+		vipsLayout = "dz"
+	}
+	suffix := "." + format
+	if format != "png" && opts.Quality > 0 {
+		suffix += fmt.Sprintf("[Q=%d]", opts.Quality)
+	}
+	args := []string{
+		"dzsave", inputPath, name,
+		"--layout", vipsLayout,
+		"--tile-size", strconv.Itoa(opts.TileSize),
+		"--overlap", strconv.Itoa(opts.Overlap),
+		"--suffix", suffix,
+	}
+	output, err := exec.Command(cmdPath, args...).CombinedOutput()
+	if err != nil {
+		return "", apperror.InvalidInput("瓦片生成失败: "+strings.TrimSpace(string(output)), err)
+	}
+	switch layout {
+	case "dzi":
+		return name + ".dzi", nil
+	case "zoomify":
+		return filepath.Join(name, "ImageProperties.xml"), nil
+	default:
+		return name, nil
+	}
+}
+
+func goTiles(buf []byte, name, layout, format string, opts TilesOptions) (string, error) {
+	img, err := decodeRaster(buf)
+	if err != nil {
+		return "", err
+	}
+	outType, err := ImageTypeFromFormat(format)
+	if err != nil {
+		return "", err
+	}
+	// levels[0] 为原图，其后依次减半。
+	levels := []*image.NRGBA{img}
+	stopAt := 1
+	if layout != "dzi" {
+		stopAt = opts.TileSize
+	}
+	for {
+		last := levels[len(levels)-1]
+		if last.Rect.Dx() <= stopAt && last.Rect.Dy() <= stopAt {
+			break
+		}
+		levels = append(levels, scaleNRGBA(last, (last.Rect.Dx()+1)/2, (last.Rect.Dy()+1)/2))
+	}
+	writeTile := func(path string, tile *image.NRGBA) error {
+		if err := ensureParentDir(path); err != nil {
+			return err
+		}
+		var out image.Image = tile
+		if outType == bimg.JPEG {
+			out = flattenWhite(tile)
+		}
+		data, err := encodeRaster(out, outType, opts.Quality)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return apperror.ConfigError("无法写入瓦片", err)
+		}
+		return nil
+	}
+	size := opts.TileSize
+	switch layout {
+	case "dzi":
+		for i, level := range levels {
+			levelIndex := len(levels) - 1 - i
+			w, h := level.Rect.Dx(), level.Rect.Dy()
+			for row := 0; row*size < h; row++ {
+				for col := 0; col*size < w; col++ {
+					rect := image.Rect(col*size-opts.Overlap, row*size-opts.Overlap, (col+1)*size+opts.Overlap, (row+1)*size+opts.Overlap).Intersect(level.Rect)
+					path := filepath.Join(name+"_files", strconv.Itoa(levelIndex), fmt.Sprintf("%d_%d.%s", col, row, format))
+					if err := writeTile(path, cropNRGBA(level, rect)); err != nil {
+						return "", err
+					}
+				}
+			}
+		}
+		descriptor := name + ".dzi"
+		xml := fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Image xmlns=\"http://schemas.microsoft.com/deepzoom/2008\" Format=\"%s\" Overlap=\"%d\" TileSize=\"%d\">\n  <Size Width=\"%d\" Height=\"%d\"/>\n</Image>\n",
+			format, opts.Overlap, size, img.Rect.Dx(), img.Rect.Dy())
+		if err := os.WriteFile(descriptor, []byte(xml), 0o644); err != nil {
+			return "", apperror.ConfigError("无法写入描述文件", err)
+		}
+		return descriptor, nil
+	case "zoomify":
+		index := 0
+		for tier := len(levels) - 1; tier >= 0; tier-- {
+			level := levels[tier]
+			tierIndex := len(levels) - 1 - tier
+			w, h := level.Rect.Dx(), level.Rect.Dy()
+			for row := 0; row*size < h; row++ {
+				for col := 0; col*size < w; col++ {
+					rect := image.Rect(col*size, row*size, (col+1)*size, (row+1)*size).Intersect(level.Rect)
+					path := filepath.Join(name, fmt.Sprintf("TileGroup%d", index/256), fmt.Sprintf("%d-%d-%d.%s", tierIndex, col, row, format))
+					if err := writeTile(path, cropNRGBA(level, rect)); err != nil {
+						return "", err
+					}
+					index++
+				}
+			}
+		}
+		descriptor := filepath.Join(name, "ImageProperties.xml")
+		xml := fmt.Sprintf("<IMAGE_PROPERTIES WIDTH=\"%d\" HEIGHT=\"%d\" NUMTILES=\"%d\" NUMIMAGES=\"1\" VERSION=\"1.8\" TILESIZE=\"%d\" />\n",
+			img.Rect.Dx(), img.Rect.Dy(), index, size)
+		if err := os.WriteFile(descriptor, []byte(xml), 0o644); err != nil {
+			return "", apperror.ConfigError("无法写入描述文件", err)
+		}
+		return descriptor, nil
+	default:
+		for i, level := range levels {
+			zoom := len(levels) - 1 - i
+			w, h := level.Rect.Dx(), level.Rect.Dy()
+			for row := 0; row*size < h; row++ {
+				for col := 0; col*size < w; col++ {
+					tile := image.NewNRGBA(image.Rect(0, 0, size, size))
+					draw.Draw(tile, tile.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+					draw.Draw(tile, tile.Bounds(), level, image.Pt(col*size, row*size), draw.Over)
+					path := filepath.Join(name, strconv.Itoa(zoom), strconv.Itoa(row), fmt.Sprintf("%d.%s", col, format))
+					if err := writeTile(path, tile); err != nil {
+						return "", err
+					}
+				}
+			}
+		}
+		return name, nil
+	}
+}
+
+// zoomLevels 返回 Google 布局的缩放级数（最小一级可放入单个瓦片）。
+func zoomLevels(width, height, tileSize int) int {
+	longest := float64(maxInt(width, height))
+	if longest <= float64(tileSize) {
+		return 1
+	}
+	return int(math.Ceil(math.Log2(longest/float64(tileSize)))) + 1
+}
+
+func cropNRGBA(img *image.NRGBA, rect image.Rectangle) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// ParseGrid 解析 "3x3" 形式的列数与行数。
+func ParseGrid(value string) (int, int, error) {
+	cols, rows, err := ParseTileSize(value)
+	if err != nil {
+		return 0, 0, apperror.InvalidArgument("网格格式无效，应为 列x行 (如 3x3)", err)
+	}
+	if cols > 100 || rows > 100 {
+		return 0, 0, apperror.InvalidArgument("网格过大", nil)
+	}
+	return cols, rows, nil
+}
+
+// SplitGrid 将图像切分为等大的网格块，按从左到右、从上到下编号。
+func SplitGrid(inputPath, outputDir string, opts GridOptions) ([]string, error) {
+	cols, rows, err := ParseGrid(opts.Grid)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, apperror.InvalidInput("文件不存在或无法读取", err)
+	}
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return nil, err
+	}
+	size, err := bimg.Size(buf)
+	if err != nil {
+		return nil, apperror.InvalidInput("无法读取图像尺寸", err)
+	}
+	if size.Width < cols || size.Height < rows {
+		return nil, apperror.InvalidArgument("图像尺寸小于网格", nil)
+	}
+	if !strings.HasSuffix(outputDir, string(os.PathSeparator)) {
+		outputDir += string(os.PathSeparator)
+	}
+	base := filepath.Base(inputPath)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	outputs := make([]string, 0, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			left := col * size.Width / cols
+			top := row * size.Height / rows
+			right := (col + 1) * size.Width / cols
+			bottom := (row + 1) * size.Height / rows
+			outPath, outFormat, err := ResolveOutput(OutputSpec{
+				InputPath:     fmt.Sprintf("%s_%02d%s", stem, len(outputs)+1, filepath.Ext(base)),
+				OutputArg:     outputDir,
+				DesiredFormat: opts.Format,
+				InputFormat:   FormatFromImageType(inputType),
+				Conflict:      opts.Conflict,
+				Overwrite:     opts.Overwrite,
+			})
+			if err != nil {
+				return outputs, err
+			}
+			outType, err := ImageTypeFromFormat(outFormat)
+			if err != nil {
+				return outputs, err
+			}
+			options := bimg.Options{
+				Top:        top,
+				Left:       left,
+				AreaWidth:  right - left,
+				AreaHeight: bottom - top,
+				Type:       outType,
+			}
+			if opts.Quality > 0 {
+				options.Quality = opts.Quality
+			}
+			piece, err := processImage(buf, options)
+			if err != nil {
+				return outputs, apperror.InvalidInput("图像处理失败", err)
+			}
+			if err := os.WriteFile(outPath, piece, 0o644); err != nil {
+				return outputs, apperror.ConfigError("无法写入输出文件", err)
+			}
+			outputs = append(outputs, outPath)
+		}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitGridKeepsDottedStem(t *testing.T) {
+	dir := t.TempDir()
+	src := writeGradientPNG(t, dir, 8, 8)
+	input := filepath.Join(dir, "photo.v2.png")
+	if err := os.Rename(src, input); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "tiles")
+	if err := os.Mkdir(outDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := SplitGrid(input, outDir, GridOptions{Grid: "2x2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"photo.v2_01.png", "photo.v2_02.png", "photo.v2_03.png", "photo.v2_04.png"}
+	if len(outputs) != len(want) {
+		t.Fatalf("outputs = %v, want %d tiles", outputs, len(want))
+	}
+	for i, output := range outputs {
+		if got := filepath.Base(output); got != want[i] {
+			t.Errorf("tile %d = %s, want %s", i+1, got, want[i])
+		}
+		if _, err := os.Stat(output); err != nil {
+			t.Errorf("tile %d not written: %v", i+1, err)
+		}
+	}
+}
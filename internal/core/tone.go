@@ -0,0 +1,124 @@
+package core
+
+import (
+	"image"
+	"math"
+)
+
+func srgbToLinear(value float64) float64 {
+	if value <= 0.04045 {
+		return value / 12.92
+	}
+	return math.Pow((value+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) float64 {
+	if value <= 0.0031308 {
+		return value * 12.92
+	}
+	return 1.055*math.Pow(value, 1/2.4) - 0.055
+}
+
+// applyChannelLUT 对 RGB 三个通道分别应用查找表，保留透明度。
+func applyChannelLUT(img *image.NRGBA, luts [3][256]uint8) {
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			row[i] = luts[0][row[i]]
+			row[i+1] = luts[1][row[i+1]]
+			row[i+2] = luts[2][row[i+2]]
+		}
+	}
+}
+
+// gainLUTs 在线性光空间中按通道增益生成查找表。
+func gainLUTs(gains [3]float64) [3][256]uint8 {
+	var luts [3][256]uint8
+	for c := 0; c < 3; c++ {
+		for v := 0; v < 256; v++ {
+			linear := srgbToLinear(float64(v)/255) * gains[c]
+			luts[c][v] = clampByte(linearToSRGB(math.Min(linear, 1)) * 255)
+		}
+	}
+	return luts
+}
+
+// applyExposure 按 EV 档位调整曝光，+1 表示亮度翻倍。
+func applyExposure(img *image.NRGBA, ev float64) {
+	if ev == 0 {
+		return
+	}
+	gain := math.Pow(2, ev)
+	applyChannelLUT(img, gainLUTs([3]float64{gain, gain, gain}))
+}
+
+// grayWorldBalance 按灰度世界假设自动白平衡：使 RGB 三通道的线性均值相等。
+func grayWorldBalance(img *image.NRGBA) {
+	var linear [256]float64
+	for v := range linear {
+		linear[v] = srgbToLinear(float64(v) / 255)
+	}
+	var sums [3]float64
+	count := 0
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			if row[i+3] < 128 {
+				continue
+			}
+			sums[0] += linear[row[i]]
+			sums[1] += linear[row[i+1]]
+			sums[2] += linear[row[i+2]]
+			count++
+		}
+	}
+	if count == 0 || sums[0] == 0 || sums[1] == 0 || sums[2] == 0 {
+		return
+	}
+	gray := (sums[0] + sums[1] + sums[2]) / 3
+	applyChannelLUT(img, gainLUTs([3]float64{gray / sums[0], gray / sums[1], gray / sums[2]}))
+}
+
+// autoLevel 按 0.5% 分位数拉伸直方图，三通道共用同一范围以避免偏色。
+func autoLevel(img *image.NRGBA) {
+	var hist [256]int
+	total := 0
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			if row[i+3] < 128 {
+				continue
+			}
+			hist[row[i]]++
+			hist[row[i+1]]++
+			hist[row[i+2]]++
+			total += 3
+		}
+	}
+	if total == 0 {
+		return
+	}
+	clip := total / 200
+	low, high := 0, 255
+	for count := 0; low < 255; low++ {
+		count += hist[low]
+		if count > clip {
+			break
+		}
+	}
+	for count := 0; high > 0; high-- {
+		count += hist[high]
+		if count > clip {
+			break
+		}
+	}
+	if high <= low {
+		return
+	}
+	var lut [256]uint8
+	scale := 255 / float64(high-low)
+	for v := range lut {
+		lut[v] = clampByte(float64(v-low) * scale)
+	}
+	applyChannelLUT(img, [3][256]uint8{lut, lut, lut})
+}
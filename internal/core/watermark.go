@@ -1,6 +1,8 @@
 package core
 
 import (
+	"image"
+	"image/draw"
 	"os"
 	"strings"
 
@@ -24,23 +26,36 @@ type WatermarkOptions struct {
 	StrokeWidth int
 	Background  string
 	StrokeMode  string
+	QR          string
+	QRLevel     string
+	Barcode     string
+	ModuleSize  int
+	QuietZone   int
+	Blend       string
+	Quality     int
 	Conflict    string
 }
 
 func Watermark(inputPath, outputArg string, opts WatermarkOptions) (string, error) {
-	if opts.Text != "" && opts.LogoPath != "" {
-		return "", apperror.InvalidArgument("文本水印与图片水印不可同时使用", nil)
+	sources := 0
+	for _, value := range []string{opts.Text, opts.LogoPath, opts.QR, opts.Barcode} {
+		if value != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", apperror.InvalidArgument("文本、图片、二维码与条形码水印不可同时使用", nil)
 	}
-	if opts.Text == "" && opts.LogoPath == "" {
-		return "", apperror.InvalidArgument("必须提供水印图片或文本", nil)
+	if sources == 0 {
+		return "", apperror.InvalidArgument("必须提供水印图片、文本、二维码或条形码", nil)
 	}
 	buf, err := os.ReadFile(inputPath)
 	if err != nil {
 		return "", apperror.InvalidInput("文件不存在或无法读取", err)
 	}
-	inputType := bimg.DetermineImageType(buf)
-	if inputType == bimg.UNKNOWN {
-		return "", apperror.UnsupportedFormat("无法识别输入格式", nil)
+	buf, inputType, err := NormalizeImage(buf)
+	if err != nil {
+		return "", err
 	}
 	inputFormat := FormatFromImageType(inputType)
 	outPath, outFormat, err := ResolveOutput(OutputSpec{
@@ -58,12 +73,16 @@ func Watermark(inputPath, outputArg string, opts WatermarkOptions) (string, erro
 	if err != nil {
 		return "", err
 	}
-	if !bimg.IsTypeSupportedSave(outType) {
+	if !IsTypeSupportedSave(outType) {
 		return "", apperror.UnsupportedFormat("当前环境不支持输出格式", nil)
 	}
 	if opts.Opacity <= 0 || opts.Opacity > 1 {
 		return "", apperror.InvalidArgument("不透明度必须在 0-1 之间", nil)
 	}
+	blend, err := ParseBlendMode(opts.Blend)
+	if err != nil {
+		return "", err
+	}
 	watermarkBuf, err := buildWatermarkBuffer(buf, opts)
 	if err != nil {
 		return "", err
@@ -84,8 +103,34 @@ func Watermark(inputPath, outputArg string, opts WatermarkOptions) (string, erro
 	if err != nil {
 		return "", err
 	}
+	if isAnimatedFormat(outFormat) && isAnimated(buf) {
+		transform, err := overlayFrameTransform(watermarkBuf, left, top, opts.Opacity, blend)
+		if err != nil {
+			return "", err
+		}
+		newImage, err := processAnimation(buf, outFormat, opts.Quality, transform)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+			return "", apperror.ConfigError("无法写入输出文件", err)
+		}
+		return outPath, nil
+	}
+	if blend != "normal" {
+		// 非 normal 混合在 Go 中逐像素计算，libvips 的 watermark 仅支持普通叠加。
+		newImage, err := blendWatermark(buf, watermarkBuf, left, top, opts.Opacity, blend, outType, opts.Quality)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(outPath, newImage, 0o644); err != nil {
+			return "", apperror.ConfigError("无法写入输出文件", err)
+		}
+		return outPath, nil
+	}
 	options := bimg.Options{
-		Type: outType,
+		Type:    outType,
+		Quality: opts.Quality,
 		WatermarkImage: bimg.WatermarkImage{
 			Left:    left,
 			Top:     top,
@@ -93,7 +138,7 @@ func Watermark(inputPath, outputArg string, opts WatermarkOptions) (string, erro
 			Opacity: float32(opts.Opacity),
 		},
 	}
-	newImage, err := bimg.NewImage(buf).Process(options)
+	newImage, err := processImage(buf, options)
 	if err != nil {
 		if opts.Text != "" {
 			return "", apperror.InvalidInput("文字水印处理失败，建议指定字体或缩小字号", err)
@@ -141,10 +186,6 @@ func buildWatermarkBuffer(baseBuf []byte, opts WatermarkOptions) ([]byte, error)
 	if opts.Text != "" {
 		return renderTextWatermark(opts.Text, opts.FontSize, opts.Font, opts.FontFile, opts.Color, opts.Opacity, opts.StrokeColor, opts.StrokeWidth, opts.Background, opts.StrokeMode)
 	}
-	logoBuf, err := os.ReadFile(opts.LogoPath)
-	if err != nil {
-		return nil, apperror.InvalidInput("无法读取水印图片", err)
-	}
 	if opts.Scale <= 0 || opts.Scale > 1 {
 		return nil, apperror.InvalidArgument("水印缩放比例必须在 0-1 之间", nil)
 	}
@@ -152,10 +193,6 @@ func buildWatermarkBuffer(baseBuf []byte, opts WatermarkOptions) ([]byte, error)
 	if err != nil {
 		return nil, apperror.InvalidInput("无法读取图像尺寸", err)
 	}
-	wmSize, err := bimg.Size(logoBuf)
-	if err != nil {
-		return nil, apperror.InvalidInput("无法读取水印尺寸", err)
-	}
 	shortSide := baseSize.Width
 	if baseSize.Height < shortSide {
 		shortSide = baseSize.Height
@@ -164,6 +201,24 @@ func buildWatermarkBuffer(baseBuf []byte, opts WatermarkOptions) ([]byte, error)
 	if target <= 0 {
 		return nil, apperror.InvalidArgument("水印缩放比例过小", nil)
 	}
+	if hasCodeWatermark(opts) {
+		return renderCodeWatermark(opts, target)
+	}
+	logoBuf, err := os.ReadFile(opts.LogoPath)
+	if err != nil {
+		return nil, apperror.InvalidInput("无法读取水印图片", err)
+	}
+	logoBuf, logoType, err := NormalizeImage(logoBuf)
+	if err != nil {
+		return nil, err
+	}
+	if logoType == bimg.SVG {
+		return renderSVGLogo(logoBuf, target)
+	}
+	wmSize, err := bimg.Size(logoBuf)
+	if err != nil {
+		return nil, apperror.InvalidInput("无法读取水印尺寸", err)
+	}
 	var resizeOptions bimg.Options
 	if wmSize.Width >= wmSize.Height {
 		resizeOptions = bimg.Options{Width: target}
@@ -177,6 +232,63 @@ func buildWatermarkBuffer(baseBuf []byte, opts WatermarkOptions) ([]byte, error)
 	return resized, nil
 }
 
+// renderSVGLogo 按水印目标尺寸直接栅格化 SVG，避免放大小尺寸位图。
+func renderSVGLogo(logoBuf []byte, target int) ([]byte, error) {
+	width, height, err := SVGSize(logoBuf)
+	if err != nil {
+		return nil, err
+	}
+	if width >= height {
+		logoBuf, err = renderSVGAt(logoBuf, 0, target, 0)
+	} else {
+		logoBuf, err = renderSVGAt(logoBuf, 0, 0, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := bimg.NewImage(logoBuf).Process(bimg.Options{Type: bimg.PNG})
+	if err != nil {
+		return nil, apperror.InvalidInput("水印渲染失败", err)
+	}
+	return rendered, nil
+}
+
+func blendWatermark(buf, watermarkBuf []byte, left, top int, opacity float64, blend string, outType bimg.ImageType, quality int) ([]byte, error) {
+	base, err := decodeRaster(buf)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := decodeRaster(watermarkBuf)
+	if err != nil {
+		return nil, err
+	}
+	compositeNRGBA(base, overlay, left, top, opacity, blend)
+	return encodeRaster(base, outType, quality)
+}
+
+func overlayFrameTransform(overlayBuf []byte, left, top int, opacity float64, blend string) (frameTransform, error) {
+	overlay, err := decodeRaster(overlayBuf)
+	if err != nil {
+		return nil, err
+	}
+	if blend != "normal" {
+		return func(frame *image.NRGBA) (*image.NRGBA, error) {
+			dst := cloneNRGBA(frame)
+			compositeNRGBA(dst, overlay, left, top, opacity, blend)
+			return dst, nil
+		}, nil
+	}
+	for i := 3; i < len(overlay.Pix); i += 4 {
+		overlay.Pix[i] = applyOpacity(overlay.Pix[i], opacity)
+	}
+	return func(frame *image.NRGBA) (*image.NRGBA, error) {
+		dst := cloneNRGBA(frame)
+		rect := image.Rect(left, top, left+overlay.Rect.Dx(), top+overlay.Rect.Dy())
+		draw.Draw(dst, rect, overlay, image.Point{}, draw.Over)
+		return dst, nil
+	}, nil
+}
+
 func gravityPosition(baseW, baseH, wmW, wmH int, gravity string, offsetX, offsetY int) (int, int, error) {
 	gravity = strings.ToLower(strings.TrimSpace(gravity))
 	if gravity == "" {
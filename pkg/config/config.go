@@ -88,6 +88,8 @@ type AIModel struct {
 	Provider  string `mapstructure:"provider"`
 	APIKeyEnv string `mapstructure:"api_key_env"`
 	Endpoint  string `mapstructure:"endpoint"`
+	// Task 限定模型用途 (remove-bg|enhance|remove-watermark)，为空时按提供方能力判断。
+	Task string `mapstructure:"task"`
 }
 
 type LoggingConfig struct {